@@ -0,0 +1,206 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"goli-cli/utils/outputUtils"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type batchOperation func(cf *client.Client, app *App, report applicationsUtils.ProgressReporter) error
+
+var batchOperations = map[string]batchOperation{
+	"restart":         restartApp,
+	"restart-rolling": restartAppRolling,
+	"restage":         restageApp,
+	"restage-rolling": restageAppRolling,
+}
+
+func NewBatchCmd(cf *client.Client, apps **map[string]AppData, updateLock *sync.WaitGroup) *cobra.Command {
+	var appNames, pattern string
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "batch OPERATION",
+		Short: "Restart or restage several applications in parallel",
+		Long: `Run a restart or restage on several applications of the current space in parallel.
+The applications are selected by name or by a glob pattern, the progress of every application is displayed in a live table and a summary is printed when all of them finish.
+The command exits with a non-zero status if the operation failed on any of the applications.
+
+Usage:
+  goli applications batch OPERATION (--apps <names> | --match <pattern>) [OPTIONS]
+
+Arguments:
+  OPERATION
+      The operation to run on the applications, one of:
+        restart            Stop and start the applications.
+        restart-rolling    Restart the applications with the rolling strategy.
+        restage            Restage the applications.
+        restage-rolling    Restage the applications with the rolling strategy.
+
+Options:
+  -a, --apps <names>
+      A comma-separated list of application names.
+
+  -m, --match <pattern>
+      A glob pattern the application names are matched against (e.g. 'portal-*').
+
+  -c, --concurrency <number>
+      The maximum number of applications processed at the same time (default 3).
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications batch restart-rolling --apps orders,payments,portal-ui
+      Restart the three applications with the rolling strategy.
+
+  goli applications batch restage --match 'portal-*' --concurrency 5
+      Restage all applications whose name starts with "portal-", five at a time.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			operation, ok := batchOperations[args[0]]
+			if !ok {
+				return fmt.Errorf("unknown operation '%s' - valid operations are restart, restart-rolling, restage and restage-rolling", args[0])
+			}
+			selectedApps, err := selectBatchApps(*apps, appNames, pattern)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("running %s on %d applications:\n", color.HiCyanString(args[0]), len(selectedApps))
+			for _, appName := range selectedApps {
+				fmt.Println("  " + appName)
+			}
+			if !utils.PresentSecurityQuestion() {
+				return nil
+			}
+			return RunBatch(cf, *apps, updateLock, operation, selectedApps, concurrency)
+		},
+	}
+
+	cmd.Flags().StringVarP(&appNames, "apps", "a", "", "a comma-separated list of application names")
+	cmd.Flags().StringVarP(&pattern, "match", "m", "", "a glob pattern to select the applications by name")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 3, "the maximum number of applications processed at the same time")
+	cmd.MarkFlagsMutuallyExclusive("apps", "match")
+	cmd.MarkFlagsOneRequired("apps", "match")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+// selectBatchApps returns the sorted names of the apps selected by the list or the pattern
+func selectBatchApps(appsList *map[string]AppData, appNames, pattern string) ([]string, error) {
+	var selectedApps []string
+	if pattern != "" {
+		for appName := range *appsList {
+			matched, err := path.Match(pattern, appName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %s", pattern, err.Error())
+			}
+			if matched {
+				selectedApps = append(selectedApps, appName)
+			}
+		}
+		if len(selectedApps) == 0 {
+			return nil, fmt.Errorf("no application matches '%s'", pattern)
+		}
+	} else {
+		for _, appName := range strings.Split(appNames, ",") {
+			appName = strings.TrimSpace(appName)
+			if appName == "" {
+				continue
+			}
+			if (*appsList)[appName].Name == "" {
+				return nil, fmt.Errorf("application %s do not exist", appName)
+			}
+			if !slices.Contains(selectedApps, appName) {
+				selectedApps = append(selectedApps, appName)
+			}
+		}
+		if len(selectedApps) == 0 {
+			return nil, errors.New("no application was provided")
+		}
+	}
+	sort.Strings(selectedApps)
+	return selectedApps, nil
+}
+
+func RunBatch(cf *client.Client, appsList *map[string]AppData, updateLock *sync.WaitGroup, operation batchOperation, appNames []string, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	progress := applicationsUtils.NewBatchProgress(appNames)
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	for _, appName := range appNames {
+		wg.Add(1)
+		go func(appName string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			progress.Start(appName)
+			app := NewApp(cf, appName, (*appsList)[appName].GUID, updateLock, appsList, true)
+			progress.Finish(appName, runBatchOperation(cf, app, operation, progress.Reporter(appName)))
+		}(appName)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	// the live table is only redrawn on a terminal, otherwise the final table is printed once
+	if progress.Live() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for waiting := true; waiting; {
+			select {
+			case <-done:
+				waiting = false
+			case <-ticker.C:
+				progress.Render()
+			}
+		}
+	}
+	<-done
+	progress.Render()
+
+	failed := progress.Failed()
+	fmt.Println()
+	outputUtils.PrintSuccessMessage(fmt.Sprintf("%d succeeded", len(appNames)-len(failed)))
+	if len(failed) > 0 {
+		outputUtils.PrintErrorMessage(fmt.Sprintf("%d failed: %s", len(failed), strings.Join(failed, ", ")))
+		return fmt.Errorf("the operation failed on %d of %d applications", len(failed), len(appNames))
+	}
+	return nil
+}
+
+func runBatchOperation(cf *client.Client, app *App, operation batchOperation, report applicationsUtils.ProgressReporter) error {
+	err := operation(cf, app, report)
+	if err != nil {
+		return err
+	}
+	state, err := applicationsUtils.WaitForAppStatus(cf, app.GUID, report)
+	if err != nil {
+		return err
+	}
+	if state == "CRASHED" {
+		return fmt.Errorf("app crashed - run 'goli applications logs %s --recent' for details", app.Name)
+	}
+	return nil
+}
@@ -31,6 +31,14 @@ const (
 	ShowInstances       = "Show bound instances"
 	ManipulateInstances = "Manipulate instances"
 	EnableSsh           = "Enable ssh"
+	CopyFilesOption     = "Copy files"
+	PortForwardOption   = "Port forward"
+	RunTaskOption       = "Run a task"
+	ShowTasksOption     = "Show tasks"
+	DebugOption         = "Debug the app (Node.js)"
+	HealthCheckOption   = "Health check"
+	ManifestOption      = "Generate manifest"
+	DriftOption         = "Compare with a manifest"
 	ChangeApp           = "Change app"
 	Back                = "Return to the previous menu"
 )
@@ -90,7 +98,17 @@ You can either provide the application name as an argument or navigate through a
 		NewShowLogsCmd(cf),
 		NewShowBoundInstancesCmd(cf),
 		NewManipulateInstanceCmd(cf),
-		NewSshCmd(cf))
+		NewSshCmd(cf),
+		NewCopyCmd(cf, apps, updateLock),
+		NewPortForwardCmd(cf, apps, updateLock),
+		NewRunTaskCmd(cf),
+		NewTasksCmd(cf),
+		NewCancelTaskCmd(cf, apps, updateLock),
+		NewDebugCmd(cf),
+		NewHealthCheckCmd(cf),
+		NewBatchCmd(cf, apps, updateLock),
+		NewManifestCmd(cf),
+		NewDriftCmd(cf))
 
 	// create a raw flag for retuning the raw applications data - for completion
 	cmd.Flags().BoolP("raw", "", false, "return all of applications by name")
@@ -118,7 +136,7 @@ func listOptions(cf *client.Client, app *App, appsList *map[string]AppData, upda
 	var option string
 	var err error
 
-	options := []string{Details, Restart, RestartRolling, Restage, RestageRolling, ConnectToPostgres, ConnectToRedis, ShowEnvs, AddEnv, ChangeEnv, ShowLogs, ShowRecentLogs, ShowInstances, ManipulateInstances, EnableSsh, ChangeApp, Back}
+	options := []string{Details, Restart, RestartRolling, Restage, RestageRolling, ConnectToPostgres, ConnectToRedis, ShowEnvs, AddEnv, ChangeEnv, ShowLogs, ShowRecentLogs, ShowInstances, ManipulateInstances, EnableSsh, CopyFilesOption, PortForwardOption, RunTaskOption, ShowTasksOption, DebugOption, HealthCheckOption, ManifestOption, DriftOption, ChangeApp, Back}
 
 	for {
 		fmt.Println("selected app: ", app.Name)
@@ -170,10 +188,10 @@ func executeOption(cf *client.Client, app *App, option string) error {
 		err = RestageAppRolling(cf, app)
 	case ConnectToPostgres:
 		fmt.Println("connecting to postgres...")
-		err = ConnectAppToPostgres(cf, app)
+		err = ConnectAppToPostgres(cf, app, 0, false)
 	case ConnectToRedis:
 		fmt.Println("connecting to redis")
-		err = ConnectAppToRedis(cf, app)
+		err = ConnectAppToRedis(cf, app, 0, false)
 	case ShowEnvs:
 		fmt.Println("showing envs")
 		err = ShowAppEnvs(cf, app)
@@ -200,10 +218,43 @@ func executeOption(cf *client.Client, app *App, option string) error {
 		err = ManipulateAppInstances(cf, app)
 	case EnableSsh:
 		err = applicationsUtils.EnableAppSsh(cf, app.GUID)
+	case CopyFilesOption:
+		fmt.Println("copying files")
+		err = CopyFilesInteractive(cf, app)
+	case PortForwardOption:
+		fmt.Println("port forwarding")
+		mappings := utils.StringPrompt("Enter the mappings (in the format: 'LOCAL_PORT:REMOTE_HOST:REMOTE_PORT', separated by spaces):")
+		err = PortForward(cf, app, strings.Fields(mappings), 0)
+	case RunTaskOption:
+		fmt.Println("running a task")
+		err = RunTaskInteractive(cf, app)
+	case ShowTasksOption:
+		fmt.Println("showing tasks")
+		err = ShowTasks(cf, app, 20)
+	case DebugOption:
+		fmt.Println("debugging the app")
+		err = DebugApp(cf, app, 0, 9229)
+	case HealthCheckOption:
+		fmt.Println("showing health check")
+		err = UpdateHealthCheckInteractive(cf, app)
+	case ManifestOption:
+		fmt.Println("generating manifest")
+		err = GenerateManifestInteractive(cf, app)
+	case DriftOption:
+		fmt.Println("comparing with a manifest")
+		err = DetectDriftInteractive(cf, app)
 	}
 	return err
 }
 
+// resolveApp creates the app from its name, for commands that get more than the app name as arguments
+func resolveApp(cf *client.Client, appsList *map[string]AppData, updateLock *sync.WaitGroup, appName string) (*App, error) {
+	if (*appsList)[appName].Name == "" {
+		return nil, errors.New("application do not exist")
+	}
+	return NewApp(cf, appName, (*appsList)[appName].GUID, updateLock, appsList, false), nil
+}
+
 func appsCompletion(appsList *map[string]AppData) {
 	for appName, _ := range *appsList {
 		fmt.Println(appName)
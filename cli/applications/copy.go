@@ -0,0 +1,126 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	. "goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"strings"
+	"sync"
+)
+
+func NewCopyCmd(cf *client.Client, apps **map[string]AppData, updateLock *sync.WaitGroup) *cobra.Command {
+	var index int
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:   "cp SOURCE DESTINATION",
+		Short: "Copy files between an application container and the local machine",
+		Long: `Copy files or directories from an application container to the local machine, or from the local machine into the container.
+The copy is done over the same SSH connection goli opens for the database tunnels, so SSH must be enabled for the application.
+Exactly one of the paths must be prefixed with the application name ('APP_NAME:/path/in/container').
+
+Usage:
+  goli applications cp APP_NAME:REMOTE_PATH LOCAL_PATH [OPTIONS]
+  goli applications cp LOCAL_PATH APP_NAME:REMOTE_PATH [OPTIONS]
+
+Arguments:
+  SOURCE
+      The file or directory to copy, either a local path or 'APP_NAME:REMOTE_PATH'.
+
+  DESTINATION
+      Where to copy to, either a local path or 'APP_NAME:REMOTE_PATH'.
+      If the destination is an existing directory, the source is copied into it.
+
+Options:
+  -i, --index <number>
+      The index of the application instance to copy from or to (default 0).
+
+  -r, --recursive
+      Copy directories recursively.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications cp my-app:/tmp/heap.heapsnapshot .
+      Copy the heap snapshot from the first instance of "my-app" into the current directory.
+
+  goli applications cp my-app:/home/vcap/app/reports ./reports --recursive --index 1
+      Copy the reports directory from the second instance of "my-app".
+
+  goli applications cp ./config.json my-app:/tmp/
+      Copy a local file into the /tmp directory of the "my-app" container.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return CopyFilesCmdFunc(cf, *apps, updateLock, args[0], args[1], index, recursive)
+		},
+	}
+
+	cmd.Flags().IntVarP(&index, "index", "i", 0, "the index of the application instance")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "copy directories recursively")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+// splitRemotePath returns the app name and the path in the container if the argument is in the 'APP_NAME:PATH' format
+func splitRemotePath(appsList *map[string]AppData, arg string) (string, string, bool) {
+	appName, remotePath, found := strings.Cut(arg, ":")
+	if !found || (*appsList)[appName].Name == "" {
+		return "", "", false
+	}
+	return appName, remotePath, true
+}
+
+func CopyFilesCmdFunc(cf *client.Client, appsList *map[string]AppData, updateLock *sync.WaitGroup, source, destination string, index int, recursive bool) error {
+	sourceApp, sourcePath, isDownload := splitRemotePath(appsList, source)
+	destinationApp, destinationPath, isUpload := splitRemotePath(appsList, destination)
+	if isDownload == isUpload {
+		return errors.New("exactly one of the paths must be in the format APP_NAME:REMOTE_PATH")
+	}
+
+	if isDownload {
+		app := NewApp(cf, sourceApp, (*appsList)[sourceApp].GUID, updateLock, appsList, false)
+		return CopyFiles(cf, app, sourcePath, destination, true, index, recursive)
+	}
+	app := NewApp(cf, destinationApp, (*appsList)[destinationApp].GUID, updateLock, appsList, false)
+	return CopyFiles(cf, app, source, destinationPath, false, index, recursive)
+}
+
+func CopyFiles(cf *client.Client, app *App, remotePath, localPath string, isDownload bool, index int, recursive bool) error {
+	sshClient, err := db.OpenSshClient(cf, app.GUID, app.Name, index)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	if isDownload {
+		fmt.Printf("copying %s from %s to %s\n", color.HiCyanString(remotePath), color.HiCyanString("%s/%d", app.Name, index), color.HiCyanString(localPath))
+		return applicationsUtils.DownloadFromContainer(sshClient, remotePath, localPath, recursive)
+	}
+	fmt.Printf("copying %s to %s:%s\n", color.HiCyanString(localPath), color.HiCyanString("%s/%d", app.Name, index), color.HiCyanString(remotePath))
+	return applicationsUtils.UploadToContainer(sshClient, localPath, remotePath, recursive)
+}
+
+func CopyFilesInteractive(cf *client.Client, app *App) error {
+	const (
+		download = "Copy from the container"
+		upload   = "Copy to the container"
+	)
+	direction, _ := utils.ListAndSelectItem([]string{download, upload}, "select a direction:", false)
+	remotePath := utils.StringPrompt("Enter the path in the container:")
+	localPath := utils.StringPrompt("Enter the local path:")
+	if remotePath == "" || localPath == "" {
+		return errors.New("both paths must be provided")
+	}
+	index := utils.IntPrompt("Enter the instance index:")
+	return CopyFiles(cf, app, remotePath, localPath, direction == download, index, true)
+}
@@ -0,0 +1,143 @@
+package applications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	. "goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the inspector of node listens on this port inside the container once it receives SIGUSR1
+const nodeInspectorPort = "9229"
+
+// finds the pid of the oldest node process in the container and opens its inspector
+const openInspectorCommand = `pid=$(pgrep -o -x node || pidof -s node); if [ -z "$pid" ]; then echo "no node process found" >&2; exit 1; fi; kill -USR1 $pid && echo $pid`
+
+func NewDebugCmd(cf *client.Client) *cobra.Command {
+	var index, localPort int
+
+	cmd := &cobra.Command{
+		Use:   "debug APP_NAME",
+		Short: "Attach a debugger to the Node.js process of an application",
+		Long: `Open the Node.js inspector of a running application instance and forward it to the local machine.
+This command connects to the container over SSH, sends SIGUSR1 to the node process to open the inspector, forwards the inspector port locally and prints the URLs to attach Chrome DevTools with.
+The tunnel is closed when the command exits.
+
+Usage:
+  goli applications debug APP_NAME [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application to debug.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -i, --index <number>
+      The index of the application instance to debug (default 0).
+
+  -p, --local-port <port>
+      The local port to forward the inspector to (default 9229).
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications debug my-app
+      Open the inspector of the first instance of "my-app" on local port 9229.
+
+  goli applications debug my-app --index 2 --local-port 9300
+      Open the inspector of the third instance of "my-app" on local port 9300.
+
+  Press 'Enter' or 'Ctrl+C' to close the tunnel.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			return DebugApp(cf, app, index, localPort)
+		},
+	}
+
+	cmd.Flags().IntVarP(&index, "index", "i", 0, "the index of the application instance")
+	cmd.Flags().IntVarP(&localPort, "local-port", "p", 9229, "the local port to forward the inspector to")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func DebugApp(cf *client.Client, app *App, index, localPort int) error {
+	sshClient, err := db.OpenSshClient(cf, app.GUID, app.Name, index)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("opening the inspector of the node process...")
+	pid, err := db.RunSshCommand(sshClient, openInspectorCommand)
+	if err != nil {
+		sshClient.Close()
+		if pid != "" {
+			return errors.New(pid)
+		}
+		return err
+	}
+	fmt.Println("SIGUSR1 sent to node process with pid", color.HiCyanString(pid))
+
+	mapping := &PortMapping{LocalPort: strconv.Itoa(localPort), RemoteHost: "localhost", RemotePort: nodeInspectorPort}
+	forwarders, err := db.StartPortForwarding(sshClient, []*PortMapping{mapping})
+	if err != nil {
+		sshClient.Close()
+		return err
+	}
+	defer db.StopPortForwarding(sshClient, forwarders)
+
+	inspectorAddress := "127.0.0.1:" + mapping.LocalPort
+	targetID, err := getInspectorTargetID(inspectorAddress)
+	if err != nil {
+		return err
+	}
+
+	outputUtils.PrintSuccessMessage("The inspector is available on", inspectorAddress)
+	fmt.Println("DevTools URL:  ", color.HiCyanString("devtools://devtools/bundled/js_app.html?experiments=true&v8only=true&ws=%s/%s", inspectorAddress, targetID))
+	fmt.Println("Chrome inspect:", color.HiCyanString("chrome://inspect"), "(add", inspectorAddress, "to the network targets)")
+	fmt.Printf("click on '%s' or '%s' to close the tunnel\n", color.HiRedString("Enter"), color.HiRedString("Ctrl+C"))
+
+	utils.StopUntilEnterOrInterrupt()
+	fmt.Println("Closing the tunnel...")
+	return nil
+}
+
+// getInspectorTargetID waits for the inspector to be opened and returns the id of its debug target
+func getInspectorTargetID(inspectorAddress string) (string, error) {
+	var targets []struct {
+		ID string `json:"id"`
+	}
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		time.Sleep(time.Second)
+		var res *http.Response
+		res, err = httpClient.Get("http://" + inspectorAddress + "/json/list")
+		if err != nil {
+			continue
+		}
+		err = json.NewDecoder(res.Body).Decode(&targets)
+		res.Body.Close()
+		if err == nil && len(targets) > 0 {
+			return targets[0].ID, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no debug target found")
+	}
+	return "", errors.New("the inspector is not available: " + strings.TrimSpace(err.Error()))
+}
@@ -0,0 +1,119 @@
+package applications
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"goli-cli/utils/outputUtils"
+)
+
+func NewDriftCmd(cf *client.Client) *cobra.Command {
+	var manifestPath string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "drift APP_NAME",
+		Short: "Compare a local manifest with the live state of an application",
+		Long: `Compare a local manifest against the live state of an application and print the differences with their severity.
+The instances, memory, disk, command and health checks of every process, the routes, env vars, bound services, buildpacks and stack are compared.
+Only the attributes that are set in the manifest are checked, except for routes, services and env vars that exist only on the live app, which are reported as well.
+The values of env vars that look like secrets are masked, and env vars with ((placeholder)) values are only checked for existence.
+The command exits with a non-zero status if any drift is found, so it can be used in scheduled checks.
+
+Usage:
+  goli applications drift APP_NAME -f <manifest> [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -f, --file <manifest>
+      The path of the manifest to compare. This is a required flag.
+      If the manifest contains several applications, the one with the same name is used.
+
+  -j, --json
+      Print the differences as JSON.
+
+  -h, --help
+      Display this help message and exit.
+
+Severities:
+  HIGH      instances, memory, missing processes, routes or services
+  MEDIUM    disk, command, health checks, buildpacks, stack, env vars and routes or services that are not in the manifest
+  LOW       env vars that are set only on the live app
+
+Examples:
+  goli applications drift my-app -f manifest.yml
+      Compare manifest.yml with the live state of "my-app".
+
+  goli applications drift my-app -f deploy/manifest.yml --json
+      Print the differences as JSON, e.g. for a nightly check.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			return DetectDrift(cf, app, manifestPath, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVarP(&manifestPath, "file", "f", "", "the path of the manifest")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "print the differences as JSON")
+	cmd.MarkFlagRequired("file")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func DetectDrift(cf *client.Client, app *App, manifestPath string, jsonOutput bool) error {
+	manifestApp, err := applicationsUtils.LoadManifestApp(manifestPath, app.Name)
+	if err != nil {
+		return err
+	}
+	env, err := app.GetEnv(cf)
+	if err != nil {
+		return err
+	}
+	services, err := boundServiceNames(cf, app)
+	if err != nil {
+		return err
+	}
+	liveManifest, _, err := applicationsUtils.GenerateManifest(cf, app.GUID, env, services, false)
+	if err != nil {
+		return err
+	}
+
+	items := applicationsUtils.DetectDrift(manifestApp, liveManifest.Applications[0])
+	if jsonOutput {
+		if items == nil {
+			items = []*applicationsUtils.DriftItem{}
+		}
+		itemsJson, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(itemsJson))
+	} else if len(items) > 0 {
+		fmt.Println("drift between", color.HiCyanString(manifestPath), "and", color.HiCyanString(app.Name)+":")
+		applicationsUtils.PrintDrift(items)
+	}
+
+	if len(items) > 0 {
+		return fmt.Errorf("%d differences found", len(items))
+	}
+	if !jsonOutput {
+		outputUtils.PrintSuccessMessage("No drift found,", app.Name, "matches", manifestPath)
+	}
+	return nil
+}
+
+func DetectDriftInteractive(cf *client.Client, app *App) error {
+	manifestPath := utils.StringPrompt("Enter the path of the manifest:")
+	return DetectDrift(cf, app, manifestPath, false)
+}
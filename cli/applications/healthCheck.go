@@ -0,0 +1,141 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+)
+
+func NewHealthCheckCmd(cf *client.Client) *cobra.Command {
+	var update applicationsUtils.HealthCheckUpdate
+	var restart bool
+
+	cmd := &cobra.Command{
+		Use:     "health-check APP_NAME",
+		Aliases: []string{"hc"},
+		Short:   "Display or update the health check of an application",
+		Long: `Display the health check and readiness check configuration of the web process of an application, or update its health check.
+A wrong health check type or timeout is a common cause of crash loops, since Cloud Foundry restarts instances whose health check fails.
+A health check update takes effect only after the application is restarted.
+
+Usage:
+  goli applications health-check APP_NAME [OPTIONS]
+
+Aliases:
+  health-check, hc
+
+Arguments:
+  APP_NAME
+      The name of the application.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -s, --set <type>
+      Update the health check type. Supported values are http, port and process.
+
+  -e, --endpoint <path>
+      The endpoint called by an http health check (e.g. /health).
+
+  -t, --timeout <seconds>
+      The time in seconds the health check can fail before the instance is considered crashed at startup.
+
+  --invocation-timeout <seconds>
+      The timeout in seconds for a single health check request.
+
+  -r, --restart
+      Restart the application with the rolling strategy after the update. Can only be used together with an update option.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications health-check my-app
+      Display the health check and readiness check of "my-app".
+
+  goli applications health-check my-app --set http --endpoint /health --timeout 60
+      Change the health check of "my-app" to an http check on /health with a 60 seconds timeout.
+
+  goli applications health-check my-app --timeout 120 --restart
+      Raise the startup timeout of "my-app" and restart it with the rolling strategy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			if update == (applicationsUtils.HealthCheckUpdate{}) {
+				if restart {
+					return errors.New("--restart can only be used with --set, --endpoint, --timeout or --invocation-timeout")
+				}
+				return ShowHealthCheck(cf, app)
+			}
+			return UpdateHealthCheck(cf, app, &update, restart)
+		},
+	}
+
+	cmd.Flags().StringVarP(&update.Type, "set", "s", "", "the health check type (http, port or process)")
+	cmd.Flags().StringVarP(&update.Endpoint, "endpoint", "e", "", "the endpoint of an http health check")
+	cmd.Flags().IntVarP(&update.Timeout, "timeout", "t", 0, "the startup timeout of the health check in seconds")
+	cmd.Flags().IntVarP(&update.InvocationTimeout, "invocation-timeout", "", 0, "the timeout of a single health check request in seconds")
+	cmd.Flags().BoolVarP(&restart, "restart", "r", false, "restart the app with the rolling strategy after the update")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ShowHealthCheck(cf *client.Client, app *App) error {
+	process, err := applicationsUtils.GetWebProcess(cf, app.GUID)
+	if err != nil {
+		return err
+	}
+	applicationsUtils.PrintHealthCheck(process)
+	return nil
+}
+
+func UpdateHealthCheck(cf *client.Client, app *App, update *applicationsUtils.HealthCheckUpdate, restart bool) error {
+	process, err := applicationsUtils.GetWebProcess(cf, app.GUID)
+	if err != nil {
+		return err
+	}
+	fmt.Println("current configuration of", color.HiCyanString(app.Name))
+	applicationsUtils.PrintHealthCheck(process)
+	fmt.Println()
+	if !utils.PresentSecurityQuestion() {
+		return nil
+	}
+
+	process, err = applicationsUtils.UpdateHealthCheck(cf, process, update)
+	if err != nil {
+		return err
+	}
+	fmt.Println("health check updated:")
+	applicationsUtils.PrintHealthCheck(process)
+
+	if !restart {
+		restart = utils.QuestionPrompt("The change takes effect after a restart, do you want to restart the app with the rolling strategy?")
+	}
+	if restart {
+		return RestartAppRolling(cf, app)
+	}
+	return nil
+}
+
+func UpdateHealthCheckInteractive(cf *client.Client, app *App) error {
+	err := ShowHealthCheck(cf, app)
+	if err != nil {
+		return err
+	}
+	if !utils.QuestionPrompt("Do you want to update the health check?") {
+		return nil
+	}
+	update := &applicationsUtils.HealthCheckUpdate{}
+	update.Type, _ = utils.ListAndSelectItem([]string{"http", "port", "process"}, "select the health check type:", false)
+	if update.Type == "http" {
+		update.Endpoint = utils.StringPrompt("Enter the endpoint (e.g. /health):")
+	}
+	update.Timeout = utils.IntPrompt("Enter the timeout in seconds:")
+	return UpdateHealthCheck(cf, app, update, false)
+}
@@ -0,0 +1,121 @@
+package applications
+
+import (
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"goli-cli/utils/outputUtils"
+	"os"
+	"strings"
+)
+
+func NewManifestCmd(cf *client.Client) *cobra.Command {
+	var outputFile string
+	var placeholders bool
+
+	cmd := &cobra.Command{
+		Use:   "manifest APP_NAME",
+		Short: "Generate a manifest from the live state of an application",
+		Long: `Reconstruct a Cloud Foundry manifest from the live state of an application.
+The manifest contains the processes with their instances, memory, disk and health checks, the routes, the buildpacks, the stack, the user-provided env vars and the names of the bound services.
+By default the manifest is printed to the terminal.
+
+Usage:
+  goli applications manifest APP_NAME [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -o, --out <file>
+      Write the manifest to the specified file instead of printing it.
+
+  -p, --placeholders
+      Replace the values of env vars that look like secrets (e.g. *_PASSWORD, *_TOKEN) with ((VAR_NAME)) placeholders,
+      so the manifest can be committed and the values supplied with '--vars-file' on push.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications manifest my-app
+      Print the manifest of "my-app".
+
+  goli applications manifest my-app --out manifest.yml --placeholders
+      Write the manifest of "my-app" to manifest.yml with placeholders instead of secret values.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			return GenerateManifest(cf, app, outputFile, placeholders)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "out", "o", "", "the file to write the manifest to")
+	cmd.Flags().BoolVarP(&placeholders, "placeholders", "p", false, "replace secret env values with placeholders")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func GenerateManifest(cf *client.Client, app *App, outputFile string, placeholders bool) error {
+	env, err := app.GetEnv(cf)
+	if err != nil {
+		return err
+	}
+	services, err := boundServiceNames(cf, app)
+	if err != nil {
+		return err
+	}
+
+	manifest, replacedEnvs, err := applicationsUtils.GenerateManifest(cf, app.GUID, env, services, placeholders)
+	if err != nil {
+		return err
+	}
+	manifestYaml, err := applicationsUtils.MarshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		fmt.Print(string(manifestYaml))
+	} else {
+		err = os.WriteFile(outputFile, manifestYaml, 0644)
+		if err != nil {
+			return err
+		}
+		outputUtils.PrintSuccessMessage("The manifest was written to", outputFile)
+	}
+	if len(replacedEnvs) > 0 {
+		fmt.Println("env vars replaced by placeholders:", color.HiCyanString(strings.Join(replacedEnvs, ", ")))
+	}
+	return nil
+}
+
+func boundServiceNames(cf *client.Client, app *App) ([]string, error) {
+	vcapServices, err := app.GetVcapServices(cf)
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	if vcapServices != nil {
+		for _, instances := range *vcapServices {
+			for _, instance := range instances {
+				services = append(services, instance.Name)
+			}
+		}
+	}
+	return services, nil
+}
+
+func GenerateManifestInteractive(cf *client.Client, app *App) error {
+	outputFile := utils.StringPrompt("Enter the file to write the manifest to (press 'Enter' to print it):")
+	placeholders := utils.QuestionPrompt("Do you want to replace secret env values with placeholders?")
+	return GenerateManifest(cf, app, outputFile, placeholders)
+}
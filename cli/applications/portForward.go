@@ -0,0 +1,130 @@
+package applications
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	. "goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func NewPortForwardCmd(cf *client.Client, apps **map[string]AppData, updateLock *sync.WaitGroup) *cobra.Command {
+	var index int
+
+	cmd := &cobra.Command{
+		Use:     "port-forward APP_NAME LOCAL_PORT:REMOTE_HOST:REMOTE_PORT [...]",
+		Aliases: []string{"pf"},
+		Short:   "Forward local ports through an application container",
+		Long: `Forward one or more local ports to hosts reachable from an application container, using the application's SSH access.
+This allows reaching internal HTTP services, debug ports or other backing services that are only reachable from inside Cloud Foundry.
+The number of active and total connections is displayed while the forwarding is running.
+
+Usage:
+  goli applications port-forward APP_NAME LOCAL_PORT:REMOTE_HOST:REMOTE_PORT [...] [OPTIONS]
+
+Aliases:
+  port-forward, pf
+
+Arguments:
+  APP_NAME
+      The name of the application whose container is used for the forwarding.
+      This is a required argument and must be specified before the mappings.
+
+  LOCAL_PORT:REMOTE_HOST:REMOTE_PORT
+      A mapping from a local port to a host and port reachable from the container.
+      Use LOCAL_PORT:REMOTE_PORT to forward to a port of the container itself.
+      Multiple mappings can be specified.
+
+Options:
+  -i, --index <number>
+      The index of the application instance to forward through (default 0).
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications port-forward my-app 8080:internal-service.internal:8080
+      Forward local port 8080 to the internal service reachable from "my-app".
+
+  goli applications port-forward my-app 9000:9000 5433:my-db-host:5432
+      Forward local port 9000 to port 9000 of the "my-app" container and local port 5433 to a database host.
+
+  Press 'Enter' or 'Ctrl+C' to stop the forwarding.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appsList := *apps
+			if (*appsList)[args[0]].Name == "" {
+				return errors.New("application do not exist")
+			}
+			app := NewApp(cf, args[0], (*appsList)[args[0]].GUID, updateLock, appsList, false)
+			return PortForward(cf, app, args[1:], index)
+		},
+	}
+
+	cmd.Flags().IntVarP(&index, "index", "i", 0, "the index of the application instance")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func PortForward(cf *client.Client, app *App, rawMappings []string, index int) error {
+	var mappings []*PortMapping
+	for _, rawMapping := range rawMappings {
+		mapping, err := db.ParsePortMapping(rawMapping)
+		if err != nil {
+			return err
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	sshClient, err := db.OpenSshClient(cf, app.GUID, app.Name, index)
+	if err != nil {
+		return err
+	}
+	forwarders, err := db.StartPortForwarding(sshClient, mappings)
+	if err != nil {
+		sshClient.Close()
+		return err
+	}
+
+	fmt.Printf("Forwarding through %s, click on '%s' or '%s' to stop\n", color.HiCyanString("%s/%d", app.Name, index), color.HiRedString("Enter"), color.HiRedString("Ctrl+C"))
+	for _, forwarder := range forwarders {
+		fmt.Printf("  127.0.0.1:%s -> %s:%s\n", color.HiCyanString(forwarder.Mapping.LocalPort), forwarder.Mapping.RemoteHost, forwarder.Mapping.RemotePort)
+	}
+
+	stop := make(chan bool)
+	go printConnectionsCounter(forwarders, stop)
+	utils.StopUntilEnterOrInterrupt()
+	stop <- true
+
+	fmt.Println("\nClosing the forwarding...")
+	db.StopPortForwarding(sshClient, forwarders)
+	return nil
+}
+
+func printConnectionsCounter(forwarders []*db.Forwarder, stop chan bool) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			var counters []string
+			for _, forwarder := range forwarders {
+				counters = append(counters, fmt.Sprintf("%s: %s active / %d total", forwarder.Mapping.LocalPort,
+					color.HiGreenString("%d", atomic.LoadInt64(&forwarder.Active)), atomic.LoadInt64(&forwarder.Total)))
+			}
+			fmt.Printf("\r%s", strings.Join(counters, " | "))
+		}
+	}
+}
@@ -13,10 +13,13 @@ import (
 )
 
 func NewPostgresCmd(cf *client.Client) *cobra.Command {
+	var localPort int
+	var console bool
+
 	cmd := &cobra.Command{
 		Use:   "postgres APP_NAME",
 		Short: "Create an SSH tunnel to the PostgreSQL bound instance",
-		Long: `Search for a PostgreSQL bound instance to the specified application, create an SSH tunnel to it, and automatically open a new connection in **TablePlus** with the provided credentials.
+		Long: `Search for a PostgreSQL bound instance to the specified application, create an SSH tunnel to it, and automatically open a new connection in **TablePlus** or the configured DB client with the provided credentials.
 This command simplifies the process of accessing a PostgreSQL database that is bound to a Cloud Foundry application by securely connecting to it through an SSH tunnel and launching TablePlus for easy interaction with the database.
 
 Usage:
@@ -28,26 +31,49 @@ Arguments:
       This is a required argument and must be specified before any options.
 
 Options:
+  -p, --local-port <port>
+      The local port to open the tunnel on.
+      If not specified, port 5432 is used, or a free port when 5432 is occupied.
+
+  -c, --console
+      Open the built-in SQL console instead of the DB client.
+      The console supports multi-line queries ending with ';', history, and the commands
+      \dt (list tables), \d TABLE (describe a table), \x (expanded display), \timing and \q (quit).
+
   -h, --help                 
       Display this help message and exit.  
 
+Clients:
+  The client is set in the "dbClients" section of config.json in the goli folder, e.g. {"dbClients": {"postgres": "psql"}}.
+  The supported clients are tableplus (the default), psql, pgcli and dbeaver. Any other value is used as a custom command
+  with the placeholders {host}, {port}, {user}, {password} and {db}, and the password is passed in PGPASSWORD.
+
 Examples:
   goli applications postgres my-app
-      Create an SSH tunnel to the PostgreSQL bound instance for the "my-app" application and open a new connection in TablePlus.`,
+      Create an SSH tunnel to the PostgreSQL bound instance for the "my-app" application and open a new connection in TablePlus.
+
+  goli applications postgres my-app --local-port 15432
+      Create the SSH tunnel on local port 15432, e.g. when a local PostgreSQL is running.
+
+  goli applications postgres my-app --console
+      Query the database of "my-app" in the built-in SQL console.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// TODO: make sure application is restricting this to must have one arg
 			app := cmd.Context().Value("app").(*App)
 
-			return ConnectAppToPostgres(cf, app)
+			return ConnectAppToPostgres(cf, app, localPort, console)
 		},
 	}
 
+	cmd.Flags().IntVarP(&localPort, "local-port", "p", 0, "the local port to open the tunnel on")
+	cmd.Flags().BoolVarP(&console, "console", "c", false, "open the built-in SQL console")
+
 	cmd.SetHelpTemplate(cmd.Long)
 
 	return cmd
 }
-func ConnectAppToPostgres(cf *client.Client, app *App) error {
+func ConnectAppToPostgres(cf *client.Client, app *App, localPort int, console bool) error {
 	vcapServices, err := app.GetVcapServices(cf)
 	if err != nil {
 		if resource.IsNotAuthorizedError(err) {
@@ -76,6 +102,6 @@ func ConnectAppToPostgres(cf *client.Client, app *App) error {
 
 	fmt.Println("Open connection to", color.HiCyanString(postgresService.Name))
 
-	err = db.OpenPostgresConnection(cf, connectionInfo, app.GUID, app.Name)
+	err = db.OpenPostgresConnection(cf, connectionInfo, app.GUID, app.Name, localPort, console)
 	return err
 }
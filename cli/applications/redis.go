@@ -12,6 +12,9 @@ import (
 )
 
 func NewRedisCmd(cf *client.Client) *cobra.Command {
+	var localPort int
+	var console bool
+
 	cmd := &cobra.Command{
 		Use:   "redis APP_NAME",
 		Short: "Create an SSH tunnel to the Redis bound instance.",
@@ -27,26 +30,51 @@ Arguments:
       This is a required argument and must be specified before any options.
 
 Options:
+  -p, --local-port <port>
+      The local port to open the tunnel on.
+      If not specified, port 6380 is used, or a free port when 6380 is occupied.
+
+  -c, --console
+      Open the built-in Redis console instead of the Redis client.
+      The console runs Redis commands with history, and has the commands \scan [PATTERN] [TYPE] (list the keys with their TTLs),
+      \get KEY (print the value by its type), \allow (allow dangerous commands) and \q (quit).
+      Dangerous commands, e.g. FLUSHALL, FLUSHDB, KEYS and EVAL, are refused unless they are allowed with \allow,
+      and after \allow every one of them asks for a confirmation.
+
   -h, --help                 
       Display this help message and exit.  
 
+Clients:
+  The client is set in the "dbClients" section of config.json in the goli folder, e.g. {"dbClients": {"redis": "redis-cli"}}.
+  The supported clients are tableplus (the default) and redis-cli. Any other value is used as a custom command
+  with the placeholders {host}, {port}, {user}, {password} and {db}, and the password is passed in REDISCLI_AUTH.
+
 Examples:
   goli applications redis my-app
-      Create an SSH tunnel to the Redis bound instance for the "my-app" application and open a connection in a Redis client.`,
+      Create an SSH tunnel to the Redis bound instance for the "my-app" application and open a connection in a Redis client.
+
+  goli applications redis my-app --local-port 16380
+      Create the SSH tunnel on local port 16380.
+
+  goli applications redis my-app --console
+      Browse the keys of the Redis instance of "my-app" in the built-in Redis console.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// TODO: make sure application is restricting this to must have one arg
 			app := cmd.Context().Value("app").(*App)
 
-			return ConnectAppToRedis(cf, app)
+			return ConnectAppToRedis(cf, app, localPort, console)
 		},
 	}
 
+	cmd.Flags().IntVarP(&localPort, "local-port", "p", 0, "the local port to open the tunnel on")
+	cmd.Flags().BoolVarP(&console, "console", "c", false, "open the built-in Redis console")
+
 	cmd.SetHelpTemplate(cmd.Long)
 
 	return cmd
 }
-func ConnectAppToRedis(cf *client.Client, app *App) error {
+func ConnectAppToRedis(cf *client.Client, app *App, localPort int, console bool) error {
 	vcapServices, err := app.GetVcapServices(cf)
 	if err != nil {
 		return err
@@ -73,6 +101,6 @@ func ConnectAppToRedis(cf *client.Client, app *App) error {
 		Password: redisService.Credentials["password"].(string),
 		Dbname:   "",
 	}
-	err = db.OpenRedisConnection(cf, connectionInfo, app.GUID, app.Name, false)
+	err = db.OpenRedisConnection(cf, connectionInfo, app.GUID, app.Name, false, localPort, console)
 	return err
 }
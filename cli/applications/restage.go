@@ -2,7 +2,6 @@ package applications
 
 import (
 	"context"
-	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"github.com/spf13/cobra"
 	. "goli-cli/entities"
@@ -68,38 +67,45 @@ Examples:
 }
 
 func RestageApp(cf *client.Client, app *App) error {
-	dropletGUID, err := applicationsUtils.BuildPackage(cf, app.GUID)
+	err := restageApp(cf, app, applicationsUtils.PrintProgress)
 	if err != nil {
 		return err
 	}
-	fmt.Println("stopping the app")
-	_, err = cf.Applications.Stop(context.Background(), app.GUID)
+	return applicationsUtils.CheckAppStatus(cf, app.GUID, app.Name)
+}
+
+func RestageAppRolling(cf *client.Client, app *App) error {
+	err := restageAppRolling(cf, app, applicationsUtils.PrintProgress)
 	if err != nil {
 		return err
 	}
-	fmt.Println("setting the droplet")
-	_, err = cf.Droplets.SetCurrentAssociationForApp(context.Background(), app.GUID, dropletGUID)
+	return applicationsUtils.CheckAppStatus(cf, app.GUID, app.Name)
+}
+
+func restageApp(cf *client.Client, app *App, report applicationsUtils.ProgressReporter) error {
+	dropletGUID, err := applicationsUtils.BuildPackage(cf, app.GUID, report)
 	if err != nil {
 		return err
 	}
-	fmt.Println("starting the app")
-	_, err = cf.Applications.Start(context.Background(), app.GUID)
+	report("stopping the app")
+	_, err = cf.Applications.Stop(context.Background(), app.GUID)
 	if err != nil {
 		return err
 	}
-	err = applicationsUtils.CheckAppStatus(cf, app.GUID, app.Name)
-	return err
-}
-
-func RestageAppRolling(cf *client.Client, app *App) error {
-	dropletGUID, err := applicationsUtils.BuildPackage(cf, app.GUID)
+	report("setting the droplet")
+	_, err = cf.Droplets.SetCurrentAssociationForApp(context.Background(), app.GUID, dropletGUID)
 	if err != nil {
 		return err
 	}
-	err = applicationsUtils.CreateDeployment(cf, app.GUID, true, dropletGUID)
+	report("starting the app")
+	_, err = cf.Applications.Start(context.Background(), app.GUID)
+	return err
+}
+
+func restageAppRolling(cf *client.Client, app *App, report applicationsUtils.ProgressReporter) error {
+	dropletGUID, err := applicationsUtils.BuildPackage(cf, app.GUID, report)
 	if err != nil {
 		return err
 	}
-	err = applicationsUtils.CheckAppStatus(cf, app.GUID, app.Name)
-	return err
+	return applicationsUtils.CreateDeployment(cf, app.GUID, true, dropletGUID, report)
 }
@@ -69,7 +69,7 @@ Examples:
 
 func RestartApp(cf *client.Client, app *App) error {
 	fmt.Println("restarting application - ", color.HiCyanString(app.Name))
-	_, err := cf.Applications.Restart(context.Background(), app.GUID)
+	err := restartApp(cf, app, applicationsUtils.PrintProgress)
 	if err != nil {
 		return err
 	}
@@ -80,3 +80,13 @@ func RestartApp(cf *client.Client, app *App) error {
 func RestartAppRolling(cf *client.Client, app *App) error {
 	return applicationsUtils.RestartAppRolling(cf, app.GUID, app.Name)
 }
+
+func restartApp(cf *client.Client, app *App, report applicationsUtils.ProgressReporter) error {
+	report("restarting the app")
+	_, err := cf.Applications.Restart(context.Background(), app.GUID)
+	return err
+}
+
+func restartAppRolling(cf *client.Client, app *App, report applicationsUtils.ProgressReporter) error {
+	return applicationsUtils.CreateDeployment(cf, app.GUID, false, "", report)
+}
@@ -0,0 +1,222 @@
+package applications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"strconv"
+	"sync"
+)
+
+func NewRunTaskCmd(cf *client.Client) *cobra.Command {
+	var command, name, memory string
+	var wait bool
+
+	cmd := &cobra.Command{
+		Use:   "run-task APP_NAME",
+		Short: "Run a one-off task from the application's current droplet",
+		Long: `Run a Cloud Foundry task, such as a data fix or a one-off migration, using the current droplet of the specified application.
+With the '--wait' option the logs of the task are streamed until it finishes, and its final state and exit status are reported.
+
+Usage:
+  goli applications run-task APP_NAME --command <command> [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application whose droplet is used to run the task.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -c, --command <command>
+      The command to run in the task. This is a required flag.
+
+  -n, --name <name>
+      The name of the task. If not specified, a name is generated by Cloud Foundry.
+
+  -m, --memory <size>
+      The memory limit of the task (e.g. 512M, 1G). If not specified, the default of the app is used.
+
+  -w, --wait
+      Stream the logs of the task and wait for it to finish.
+      The command exits with an error if the task failed.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications run-task my-app --command "node scripts/fix-data.js" --wait
+      Run the data fix script in a task of "my-app" and follow its logs until it finishes.
+
+  goli applications run-task my-app -c "npm run migrate" -n migrate -m 1G
+      Run the migration in a task named "migrate" with 1G of memory without waiting for it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			memoryInMB := 0
+			if memory != "" {
+				var err error
+				memoryInMB, err = utils.ParseSizeInMB(memory)
+				if err != nil {
+					return err
+				}
+			}
+			return RunTask(cf, app, command, name, memoryInMB, wait)
+		},
+	}
+
+	cmd.Flags().StringVarP(&command, "command", "c", "", "the command to run in the task")
+	cmd.Flags().StringVarP(&name, "name", "n", "", "the name of the task")
+	cmd.Flags().StringVarP(&memory, "memory", "m", "", "the memory limit of the task (e.g. 512M, 1G)")
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "stream the logs of the task and wait for it to finish")
+	cmd.MarkFlagRequired("command")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func NewTasksCmd(cf *client.Client) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "tasks APP_NAME",
+		Short: "List the recent tasks of an application",
+		Long: `List the recent Cloud Foundry tasks of the specified application, including their state, command and failure reason.
+
+Usage:
+  goli applications tasks APP_NAME [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application for which to list the tasks.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -l, --limit <number>
+      The maximum number of tasks to display (default 20).
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications tasks my-app
+      List the 20 most recent tasks of "my-app".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := cmd.Context().Value("app").(*App)
+			return ShowTasks(cf, app, limit)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "the maximum number of tasks to display")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func NewCancelTaskCmd(cf *client.Client, apps **map[string]AppData, updateLock *sync.WaitGroup) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel-task APP_NAME TASK",
+		Short: "Cancel a running task of an application",
+		Long: `Cancel a pending or running Cloud Foundry task of the specified application.
+
+Usage:
+  goli applications cancel-task APP_NAME TASK [OPTIONS]
+
+Arguments:
+  APP_NAME
+      The name of the application the task belongs to.
+
+  TASK
+      The id (as displayed by the 'tasks' command), name or GUID of the task to cancel.
+
+Options:
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli applications cancel-task my-app 12
+      Cancel the task with id 12 of "my-app".`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := resolveApp(cf, *apps, updateLock, args[0])
+			if err != nil {
+				return err
+			}
+			if !utils.PresentSecurityQuestion() {
+				return nil
+			}
+			return CancelTask(cf, app, args[1])
+		},
+	}
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func RunTask(cf *client.Client, app *App, command, name string, memoryInMB int, wait bool) error {
+	if command == "" {
+		return errors.New("a command must be provided")
+	}
+	task, err := applicationsUtils.CreateTask(cf, app.GUID, command, name, memoryInMB)
+	if err != nil {
+		return err
+	}
+	fmt.Println("task", color.HiCyanString(task.Name), "created with id", color.HiCyanString(strconv.Itoa(task.SequenceID)), "- state:", task.State)
+	if !wait {
+		return nil
+	}
+	task, err = applicationsUtils.FollowTask(cf, app.GUID, task)
+	if err != nil {
+		return err
+	}
+	return applicationsUtils.PrintTaskResult(task)
+}
+
+func ShowTasks(cf *client.Client, app *App, limit int) error {
+	tasks, err := applicationsUtils.ListRecentTasks(cf, app.GUID, limit)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No tasks found...")
+		return nil
+	}
+	applicationsUtils.PrintTasks(tasks)
+	return nil
+}
+
+func CancelTask(cf *client.Client, app *App, identifier string) error {
+	task, err := applicationsUtils.FindTask(cf, app.GUID, identifier)
+	if err != nil {
+		return err
+	}
+	if task.State != "PENDING" && task.State != "RUNNING" {
+		return fmt.Errorf("task %s is already in state %s", task.Name, task.State)
+	}
+	fmt.Println("canceling task", color.HiCyanString(task.Name))
+	task, err = cf.Tasks.Cancel(context.Background(), task.GUID)
+	if err != nil {
+		return err
+	}
+	fmt.Println("task state:", task.State)
+	return nil
+}
+
+func RunTaskInteractive(cf *client.Client, app *App) error {
+	command := utils.StringPrompt("Enter the command of the task:")
+	name := utils.StringPrompt("Enter the name of the task (press 'Enter' for a generated name):")
+	if !utils.PresentSecurityQuestion() {
+		return nil
+	}
+	return RunTask(cf, app, command, name, 0, true)
+}
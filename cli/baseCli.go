@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/cobra"
 	"goli-cli/cli/applications"
 	"goli-cli/cli/changeTarget"
+	"goli-cli/cli/diffSpace"
+	"goli-cli/cli/graph"
 	"goli-cli/cli/instances"
 	"goli-cli/cli/teamFunctions"
 	"goli-cli/entities"
@@ -59,7 +61,8 @@ func Execute() {
 	baseCmd.CompletionOptions.HiddenDefaultCmd = true
 	err := baseCmd.Execute()
 	if err != nil {
-		outputUtils.Panic(err.Error())
+		outputUtils.PrintErrorMessage(err.Error())
+		os.Exit(1)
 	}
 }
 
@@ -106,6 +109,8 @@ func init() {
 		instances.NewCmd(cf, &instancesByOffer, &offerNames, &instancesLock), // instances command with its subcommands
 		teamFunctions.NewCmd(cf, currentUser.Role, &apps, &instancesByOffer, &offerNames, &appsLock, &instancesLock, &updateDataLock),
 		changeTarget.NewCmd(cf, &landscapes, &updateLandLock, selectedTarget, selectedOrg, selectedSpace),
+		graph.NewCmd(cf, &apps, &instancesByOffer, &appsLock, &instancesLock),
+		diffSpace.NewCmd(cf, &landscapes, &updateLandLock, selectedTarget, domain, selectedOrg, selectedSpace),
 	)
 }
 
@@ -115,10 +120,12 @@ func cli(cf *client.Client) {
 		Applications  = "Applications"
 		Instances     = "Instances"
 		TeamFunctions = "Custom team options"
+		SpaceGraph    = "Export the space graph"
+		DiffSpace     = "Compare with another space"
 		ChangeTarget  = "Change targeted org or space"
 		Exit          = "Exit"
 	)
-	options := []string{Applications, Instances, TeamFunctions, ChangeTarget, Exit}
+	options := []string{Applications, Instances, TeamFunctions, SpaceGraph, DiffSpace, ChangeTarget, Exit}
 	for {
 		option, _ := utils.ListAndSelectItem(options, "Select an option:", false)
 		var err error
@@ -145,6 +152,12 @@ func cli(cf *client.Client) {
 			instancesLock.Wait()
 			fmt.Println("Team functions of team:", currentUser.Role)
 			teamFunctions.TeamFeaturesCli(cf, currentUser.Role, apps, instancesByOffer, offerNames, &updateDataLock)
+		case SpaceGraph:
+			appsLock.Wait()
+			instancesLock.Wait()
+			err = graph.ExportGraphInteractive(cf, apps, instancesByOffer)
+		case DiffSpace:
+			err = diffSpace.DiffSpacesInteractive(cf, &landscapes, &updateLandLock, selectedTarget, domain, selectedOrg, selectedSpace)
 		case ChangeTarget:
 			selectedSpace, selectedOrg, err = changeTarget.ChangeTarget(cf, &landscapes, &updateLandLock, selectedTarget, nil, nil, "", "")
 			GetAndUpdateLandscape(cf)
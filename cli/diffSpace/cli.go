@@ -0,0 +1,153 @@
+package diffSpace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/spaceUtils"
+	"sync"
+)
+
+func NewCmd(cf *client.Client, landscapes *Landscape, updateLandLock *sync.WaitGroup, selectedTarget, domain string, currentOrg *CfOrg, currentSpace *CfSpace) *cobra.Command {
+	var orgName, spaceName, againstOrgName, againstSpaceName string
+
+	cmd := &cobra.Command{
+		Use:   "diff-space",
+		Short: "Compare the apps, service instances and bindings of two spaces",
+		Long: `Compare two spaces of the current landscape and print a grouped report of what exists only in one of them or differs.
+Apps are compared by existence, state, number of instances, memory and droplet age; blue-green suffixes (-blue, -green) are ignored when matching app names.
+If both colors of an app exist, only the started one is compared, with its bindings, and a warning names the other.
+Service instances are compared by existence, offering and plan, and bindings by the app and service instance they connect.
+The command exits with a non-zero status if the spaces differ.
+
+Usage:
+  goli diff-space [--org <org>] [--space <space>] --against-org <org> --against-space <space>
+
+Options:
+  -o, --org <org>
+      The organization of the first space. The default is the targeted organization.
+
+  -s, --space <space>
+      The first space. The default is the targeted space.
+
+  --against-org <org>
+      The organization of the space to compare with. The default is the organization of the first space.
+
+  --against-space <space>
+      The space to compare with. This is a required flag.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli diff-space --against-space staging
+      Compare the targeted space with the "staging" space of the same organization.
+
+  goli diff-space --org eu10-org --space prod --against-org us10-org --against-space prod
+      Compare the "prod" spaces of the two organizations.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if orgName == "" {
+				orgName = currentOrg.Name
+			}
+			if spaceName == "" {
+				spaceName = currentSpace.Name
+			}
+			if againstOrgName == "" {
+				againstOrgName = orgName
+			}
+			return DiffSpaces(cf, landscapes, updateLandLock, selectedTarget, domain, orgName, spaceName, againstOrgName, againstSpaceName)
+		},
+	}
+
+	cmd.Flags().StringVarP(&orgName, "org", "o", "", "the organization of the first space")
+	cmd.Flags().StringVarP(&spaceName, "space", "s", "", "the first space")
+	cmd.Flags().StringVarP(&againstOrgName, "against-org", "", "", "the organization of the space to compare with")
+	cmd.Flags().StringVarP(&againstSpaceName, "against-space", "", "", "the space to compare with")
+	cmd.MarkFlagRequired("against-space")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func DiffSpaces(cf *client.Client, landscapes *Landscape, updateLandLock *sync.WaitGroup, selectedTarget, domain, orgName, spaceName, againstOrgName, againstSpaceName string) error {
+	space, err := findSpace(landscapes, updateLandLock, selectedTarget, orgName, spaceName)
+	if err != nil {
+		return err
+	}
+	againstSpace, err := findSpace(landscapes, updateLandLock, selectedTarget, againstOrgName, againstSpaceName)
+	if err != nil {
+		return err
+	}
+	if space.GUID == againstSpace.GUID {
+		return errors.New("cannot compare a space with itself")
+	}
+
+	label := orgName + "/" + spaceName
+	againstLabel := againstOrgName + "/" + againstSpaceName
+	fmt.Println("comparing", color.HiCyanString(label), "with", color.HiCyanString(againstLabel)+"...")
+
+	var left, right *spaceUtils.SpaceSnapshot
+	var leftErr, rightErr error
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		left, leftErr = spaceUtils.LoadSpace(cf, domain, space.GUID, label)
+	}()
+	go func() {
+		defer wg.Done()
+		right, rightErr = spaceUtils.LoadSpace(cf, domain, againstSpace.GUID, againstLabel)
+	}()
+	wg.Wait()
+	if leftErr != nil {
+		return leftErr
+	}
+	if rightErr != nil {
+		return rightErr
+	}
+
+	for _, warning := range append(left.Warnings, right.Warnings...) {
+		outputUtils.PrintWarningMessage(warning)
+	}
+	groups := spaceUtils.DiffSpaces(left, right)
+	differences := spaceUtils.PrintSpaceDiff(groups, label, againstLabel)
+	fmt.Println()
+	if differences > 0 {
+		return fmt.Errorf("the spaces have %d differences", differences)
+	}
+	outputUtils.PrintSuccessMessage("The spaces are identical")
+	return nil
+}
+
+func findSpace(landscapes *Landscape, updateLandLock *sync.WaitGroup, selectedTarget, orgName, spaceName string) (*CfSpace, error) {
+	// wait for the landscapes to be refreshed, so spaces created since the last run are found
+	updateLandLock.Wait()
+	for _, org := range (*landscapes)[selectedTarget] {
+		if org.Name != orgName {
+			continue
+		}
+		for _, space := range org.Spaces {
+			if space.Name == spaceName {
+				return space, nil
+			}
+		}
+		return nil, fmt.Errorf("space %s do not exist in the organization %s", spaceName, orgName)
+	}
+	return nil, fmt.Errorf("organization %s do not exist", orgName)
+}
+
+func DiffSpacesInteractive(cf *client.Client, landscapes *Landscape, updateLandLock *sync.WaitGroup, selectedTarget, domain string, currentOrg *CfOrg, currentSpace *CfSpace) error {
+	againstOrgName := utils.StringPrompt("Enter the organization to compare with (press 'Enter' for the current organization):")
+	if againstOrgName == "" {
+		againstOrgName = currentOrg.Name
+	}
+	againstSpaceName := utils.StringPrompt("Enter the space to compare with:")
+	return DiffSpaces(cf, landscapes, updateLandLock, selectedTarget, domain, currentOrg.Name, currentSpace.Name, againstOrgName, againstSpaceName)
+}
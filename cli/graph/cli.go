@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/entities"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/graphUtils"
+	"goli-cli/utils/outputUtils"
+	"os"
+	"sync"
+)
+
+func NewCmd(cf *client.Client, apps **map[string]AppData, instances **map[string][]*entities.Instance, appsLock, instancesLock *sync.WaitGroup) *cobra.Command {
+	var format, outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Export the app to service instance binding graph of the current space",
+		Long: `Build the graph of the bindings between the applications and the service instances of the current space and export it.
+Every service instance is labeled with its offering and plan, user-provided services are included as well.
+Service instances that are not bound to any application and applications without bindings are highlighted with a dashed red border,
+and are listed as warnings when the graph is written to a file.
+
+Usage:
+  goli graph [OPTIONS]
+
+Options:
+  -f, --format <format>
+      The output format, one of:
+        dot        Graphviz, render it with 'dot -Tsvg graph.dot -o graph.svg'.
+        mermaid    Mermaid, can be pasted into markdown files and wiki pages.
+        json       The apps, instances and bindings as JSON.
+      The default is mermaid.
+
+  -o, --out <file>
+      Write the graph to the specified file instead of printing it.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli graph
+      Print the graph of the current space in the mermaid format.
+
+  goli graph --format dot --out space.dot
+      Write the graph in the Graphviz format to space.dot.`,
+		Args: cobra.NoArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			appsLock.Wait()
+			instancesLock.Wait()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ExportGraph(cf, *apps, *instances, format, outputFile)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "mermaid", "the output format (dot, mermaid or json)")
+	cmd.Flags().StringVarP(&outputFile, "out", "o", "", "the file to write the graph to")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ExportGraph(cf *client.Client, apps *map[string]AppData, instances *map[string][]*entities.Instance, format, outputFile string) error {
+	graph, err := graphUtils.BuildGraph(cf, apps, instances)
+	if err != nil {
+		return err
+	}
+	output, err := graphUtils.RenderGraph(graph, format)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		fmt.Print(output)
+	} else {
+		err = os.WriteFile(outputFile, []byte(output), 0644)
+		if err != nil {
+			return err
+		}
+		outputUtils.PrintSuccessMessage("The graph was written to", outputFile)
+		printUnbound(graph)
+	}
+	return nil
+}
+
+func printUnbound(graph *graphUtils.Graph) {
+	for _, app := range graph.Apps {
+		if !app.Bound {
+			outputUtils.PrintWarningMessage("app without bindings: " + app.Name)
+		}
+	}
+	for _, instance := range graph.Instances {
+		if !instance.Bound {
+			outputUtils.PrintWarningMessage("unbound instance: " + instance.Name)
+		}
+	}
+}
+
+func ExportGraphInteractive(cf *client.Client, apps *map[string]AppData, instances *map[string][]*entities.Instance) error {
+	format, _ := utils.ListAndSelectItem([]string{"mermaid", "dot", "json"}, "select a format:", false)
+	outputFile := utils.StringPrompt("Enter the file to write the graph to (press 'Enter' to print it):")
+	fmt.Println("building the graph of the space...")
+	return ExportGraph(cf, apps, instances, format, outputFile)
+}
@@ -64,11 +64,20 @@ func generateSubCommands(cmd *cobra.Command, role string, cf *client.Client, app
 		cmd.AddCommand(ops.NewRunSaaCmd(cf, apps, instances),
 			ops.NewSaaStatusCmd(cf, instances),
 			ops.NewRunQueryAllCmd(instances),
+			ops.NewExecSqlCmd(cf, instances),
+			ops.NewImportTableCmd(cf, instances),
+			ops.NewSchemaDiffCmd(cf, instances),
 			ops.NewCheckCertCmd(cf, apps, instances))
 	}
 
 	// shared commands
 	cmd.AddCommand(shared.NewRunQueryCmd(cf, instances),
+		shared.NewSavedQueriesCmd(),
+		shared.NewExplainCmd(cf, instances),
+		shared.NewSchemaCmd(cf, instances),
+		shared.NewDbHealthCmd(cf, instances),
+		shared.NewDbSessionsCmd(cf, instances, role == Ops),
+		shared.NewExportTableCmd(cf, instances),
 		shared.NewConnectToDbCmd(cf, instances))
 
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"goli-cli/cli/teamFunctions/shared"
+	"goli-cli/db"
 	"goli-cli/entities"
 	. "goli-cli/types"
 	"goli-cli/utils"
@@ -14,13 +15,17 @@ const (
 	RunSAA                  = "Run SAA"
 	GetStatusOfSAAJob       = "Get status of SAA job"
 	RunQueryOnAllLandscapes = "Run query on all landscapes"
+	ExecSqlScript           = "Execute SQL script"
+	ImportTable             = "Import a table from CSV"
+	SignalDbSession         = "Cancel or terminate a DB session"
+	CompareDbSchemas        = "Compare DB schemas"
 	CheckCert               = "Check certificate"
 	Back                    = "Back"
 )
 
 func OpsCli(cf *client.Client, apps *map[string]AppData, instances *map[string][]*entities.Instance, names *[]string) {
 
-	teamOptions := []string{RunSAA, GetStatusOfSAAJob, RunQueryOnAllLandscapes, CheckCert, Back}
+	teamOptions := []string{RunSAA, GetStatusOfSAAJob, RunQueryOnAllLandscapes, ExecSqlScript, ImportTable, SignalDbSession, CompareDbSchemas, CheckCert, Back}
 	options := shared.GetAllOptions(&teamOptions)
 	var err error
 	var option string
@@ -54,7 +59,17 @@ func executeOption(cf *client.Client, option string, apps *map[string]AppData, i
 		err = GetStatusOfSAAJobFun(cf, instances, "", "")
 	case RunQueryOnAllLandscapes:
 		fmt.Println("Running query on all landscapes")
-		err = RunQueryAllFun(instances, "", "")
+		err = RunQueryAllFun(instances, "", "", "", "", db.DefaultStatementTimeout, 0)
+	case ExecSqlScript:
+		fmt.Println("Executing SQL script")
+		commit := utils.StringPrompt("Commit the transaction after the results are shown? (y/n)") == "y"
+		err = ExecSqlFun(cf, instances, "", "", commit, db.DefaultStatementTimeout)
+	case ImportTable:
+		err = ImportTableFun(cf, instances, "", "", "", db.DefaultStatementTimeout)
+	case SignalDbSession:
+		err = shared.SignalDbSessionInteractive(cf, instances)
+	case CompareDbSchemas:
+		err = SchemaDiffInteractive(cf, instances)
 	case CheckCert:
 		fmt.Println("Checking certificate")
 		err = CheckCertFunc(cf, apps, "", "")
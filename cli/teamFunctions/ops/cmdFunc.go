@@ -1,14 +1,12 @@
 package ops
 
 import (
-	"context"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
-	"github.com/cloudfoundry/go-cfclient/v3/config"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"goli-cli/db"
@@ -126,7 +124,9 @@ Examples:
 }
 
 func NewRunQueryAllCmd(instances **map[string][]*entities.Instance) *cobra.Command {
-	var query, dbName string
+	var query, dbName, format, fileName string
+	var timeout time.Duration
+	var limit int
 
 	cmd := &cobra.Command{
 		Use:   "run-query-all",
@@ -146,6 +146,24 @@ Options:
       The name of the database where the query will be executed.
       If not specified, interactive mode will open to choose the database.
 
+  -t, --timeout <duration>
+      The time the query may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+      The query runs in a read-only transaction, so the database rejects any statement that writes.
+
+  -l, --limit <rows>
+      The maximum number of rows to read from every data center.
+
+  -f, --file <file>
+      The name of the file to save the query result of all data centers to.
+      If not specified, the query result will be displayed in the terminal.
+
+  --format <format>
+      The format of the query result, one of table, csv, json, ndjson, markdown and xlsx.
+      The table format prints a table for each data center, and is the default when no file is specified.
+      The other formats combine the results of all data centers, with the space of the data center in the first column "landscape".
+      In the JSON formats numbers and booleans keep their type, NULL is null and json columns are nested.
+      When saving to a file without a format, the format is taken from the file extension.
+
   -h, --help  
       Display this help message and exit.
 
@@ -153,17 +171,24 @@ Examples:
   goli team-features run-query-all -q "SELECT * FROM users" -d "my_database"  
       Execute the query "SELECT * FROM users" across all data centers for the "my_database" database.
 
+  goli team-features run-query-all -q "SELECT count(*) FROM users" -d "my_database" -f users.xlsx
+      Save the number of users of every data center to an Excel workbook.
+
   goli team-features run-query-all  
       Open interactive mode to input the query and select the database to run the query across all data centers.`,
 		Aliases: []string{"query-all"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunQueryAllFun(*instances, query, dbName)
+			return RunQueryAllFun(*instances, query, dbName, format, fileName, timeout, limit)
 		},
 	}
 
 	cmd.Flags().StringVarP(&query, "query", "q", "", "query to run on the DB")
 	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB on which the query will be run on")
+	cmd.Flags().StringVarP(&fileName, "file", "f", "", "the name of the file to save the query result to")
+	cmd.Flags().StringVarP(&format, "format", "", "", "the format of the query result")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", db.DefaultStatementTimeout, "the time the query may run")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 0, "the maximum number of rows to read from every data center")
 
 	cmd.SetHelpTemplate(cmd.Long)
 
@@ -349,11 +374,11 @@ func RunSAAFun(cf *client.Client, instances *map[string][]*entities.Instance, ap
 			return errors.New("cdm-store-service app not found")
 		}
 
-		stopChan, err := db.OpenConnectionToService(cf, postgresCred, cdmStoreApp.GUID, "postgres", cdmStoreApp.Name)
+		stopChan, localCred, err := db.OpenConnectionToService(cf, postgresCred, cdmStoreApp.GUID, "postgres", cdmStoreApp.Name, 0)
 		if err != nil {
 			return err
 		}
-		rows, err := db.RunQuery(postgresCred, `select distinct("CDM_ENTITIES"."identityZoneId") from "cdm"."CDM_ENTITIES"`, false)
+		rows, err := db.RunQuery(localCred, `select distinct("CDM_ENTITIES"."identityZoneId") from "cdm"."CDM_ENTITIES"`, false)
 
 		stopChan <- os.Interrupt
 		if err != nil {
@@ -460,38 +485,20 @@ func GetStatusOfSAAJobFun(cf *client.Client, instances *map[string][]*entities.I
 	return err
 }
 
-func RunQueryAllFun(instances *map[string][]*entities.Instance, queryInput, db string) error {
+func RunQueryAllFun(instances *map[string][]*entities.Instance, queryInput, database, format, fileName string, timeout time.Duration, limit int) error {
 	var query, dbName string
 	var postgresNames []string
-	var landscapesInfo map[string]struct {
-		API   string `json:"api"`
-		Org   string `json:"org"`
-		Space string `json:"space"`
-	}
-	var dbUserInfo struct {
-		DbCred struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		} `json:"dbCredentials"`
-	}
-	userConfRaw, err := os.ReadFile("config.json")
+	format, fileName, err := teamFunctionsUtils.ResolveQueryFormat(format, fileName)
 	if err != nil {
-		outputUtils.PrintErrorMessage("config file should be exist")
 		return err
 	}
-	err = json.Unmarshal(userConfRaw, &dbUserInfo)
-	if dbUserInfo.DbCred.Username == "" || dbUserInfo.DbCred.Password == "" {
-		return errors.New("user credentials are missing in config file")
-	}
-	infoRaw, err := os.ReadFile("resources/landscapesInfo.json")
+	landscapes, err := teamFunctionsUtils.LoadLandscapes()
 	if err != nil {
-		outputUtils.PrintErrorMessage("landscapesInfo should be exist in the resources folder with all of the landscapes")
 		return err
 	}
-	err = json.Unmarshal(infoRaw, &landscapesInfo)
 
-	if db != "" {
-		dbName = db
+	if database != "" {
+		dbName = database
 	} else {
 		for _, instance := range (*instances)["postgresql-db"] {
 			postgresNames = append(postgresNames, instance.Name)
@@ -505,40 +512,35 @@ func RunQueryAllFun(instances *map[string][]*entities.Instance, queryInput, db s
 	} else {
 		query = utils.StringPrompt("Enter the query:")
 	}
-	if !teamFunctionsUtils.ValidateQuery(query) {
-		return errors.New("invalid query - query be a 'select' query and contain 'from' clauses")
-	}
 
-	var cf *client.Client
-	for _, landscape := range landscapesInfo {
-		outputUtils.PrintSuccessMessage("******", landscape.Space, "******")
-		cfConf, err := config.New(landscape.API, config.UserPassword(dbUserInfo.DbCred.Username, dbUserInfo.DbCred.Password), config.SkipTLSValidation())
-		if err != nil {
-			return err
+	// the results of all the landscapes, for the formats other than table
+	var combined *db.QueryResult
+	for _, landscape := range landscapes {
+		if format == teamFunctionsUtils.TableFormat {
+			outputUtils.PrintSuccessMessage("******", landscape.Space, "******")
+		} else {
+			fmt.Fprintln(os.Stderr, "running the query on", landscape.Space+"...")
 		}
-		cf, err = client.New(cfConf)
+		cf, pgInstance, err := landscape.PostgresInstance(dbName)
 		if err != nil {
 			return err
 		}
-		space, _ := cf.Spaces.First(context.Background(), &client.SpaceListOptions{
-			Names: client.Filter{Values: []string{landscape.Space}},
-		})
-		pgInstance, err := cf.ServiceInstances.First(context.Background(), &client.ServiceInstanceListOptions{
-			Names:      client.Filter{Values: []string{dbName}},
-			SpaceGUIDs: client.Filter{Values: []string{space.GUID}},
-		})
+		result, err := teamFunctionsUtils.RunQueryOnInstance(cf, pgInstance, query, timeout, limit)
 		if err != nil {
 			return err
 		}
-		tempInstance := &entities.Instance{
-			Name: pgInstance.Name,
-			GUID: pgInstance.GUID,
+		if format == teamFunctionsUtils.TableFormat {
+			db.PrintQueryResult(result.StringRows(true))
+			continue
 		}
-		err = teamFunctionsUtils.ConnectAndPrintQuery(cf, tempInstance, query, "")
+		combined, err = teamFunctionsUtils.CombineQueryResults(combined, result, "landscape", landscape.Space)
 		if err != nil {
 			return err
 		}
 	}
+	if combined != nil {
+		return teamFunctionsUtils.OutputQueryResult(combined, format, fileName)
+	}
 	return nil
 }
 
@@ -0,0 +1,139 @@
+package ops
+
+import (
+	"errors"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"os"
+	"time"
+)
+
+func NewExecSqlCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName, fileName string
+	var commit bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "exec-sql",
+		Short: "Run a SQL script that changes data in a single transaction.",
+		Long: `This command runs the statements of a SQL script on the specified database in a single transaction, and shows the number of rows affected by each statement.
+By default the command is a dry run: the transaction is rolled back after the statements ran, so nothing is changed.
+With --commit the transaction is committed after the results are shown and the change is confirmed.
+If a statement fails, the transaction is rolled back and the failed statement is shown.
+
+The script must not contain BEGIN, COMMIT, ROLLBACK or other statements that end the transaction.
+Every run is appended to sqlAudit.log in the goli folder, with the script, the user, the org and space, the database and the result.
+
+Usage:
+  goli team-features exec-sql -f FILE [OPTIONS]
+
+Options:
+  -f, --file <file>
+      The path of the SQL script, with the statements separated by semicolons.
+      This is a required flag and must be specified.
+
+  -d, --db <database>
+      The name of the database where the script will be run.
+      If not specified, interactive mode will open to choose the database.
+
+  --commit
+      Commit the transaction after confirming the results, instead of rolling it back.
+
+  -t, --timeout <duration>
+      The time each statement may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features exec-sql -d "my_database" -f fix.sql
+      Run fix.sql on the "my_database" database and roll it back, to see how many rows it changes.
+
+  goli team-features exec-sql -d "my_database" -f fix.sql --commit
+      Run fix.sql on the "my_database" database and commit it after confirming the results.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ExecSqlFun(cf, *instances, dbName, fileName, commit, timeout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB on which the script will be run on")
+	cmd.Flags().StringVarP(&fileName, "file", "f", "", "the path of the SQL script")
+	cmd.Flags().BoolVarP(&commit, "commit", "", false, "commit the transaction instead of rolling it back")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", db.DefaultStatementTimeout, "the time each statement may run")
+	_ = cmd.MarkFlagRequired("file")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ExecSqlFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName, fileName string, commit bool, timeout time.Duration) error {
+	if fileName == "" {
+		fileName = utils.StringPrompt("Enter the path of the SQL script:")
+	}
+	script, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	statements, err := teamFunctionsUtils.SplitSQLStatements(string(script))
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		return errors.New("the script has no statements")
+	}
+
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+
+	outcome := teamFunctionsUtils.OutcomeDryRun
+	results, committed, err := teamFunctionsUtils.ExecScriptOnInstance(cf, pgInstanceRaw, statements, timeout, func(results []*db.StatementResult) bool {
+		teamFunctionsUtils.PrintStatementResults(results)
+		if !commit {
+			return false
+		}
+		if !utils.PresentSecurityQuestion() {
+			outcome = teamFunctionsUtils.OutcomeDeclined
+			return false
+		}
+		return true
+	})
+
+	entry := &teamFunctionsUtils.SqlAuditEntry{Db: pgInstanceRaw.Name, File: fileName, Script: string(script), Commit: commit, Statements: results}
+	switch {
+	case err != nil:
+		if len(results) > 0 {
+			teamFunctionsUtils.PrintStatementResults(results)
+		}
+		entry.Outcome = teamFunctionsUtils.OutcomeFailed
+		entry.Error = err.Error()
+	case committed:
+		entry.Outcome = teamFunctionsUtils.OutcomeCommitted
+	default:
+		entry.Outcome = outcome
+	}
+	if auditErr := teamFunctionsUtils.AppendSqlAuditEntry(entry); auditErr != nil {
+		outputUtils.PrintWarningMessage("Failed to write the audit log:", auditErr.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	switch entry.Outcome {
+	case teamFunctionsUtils.OutcomeCommitted:
+		outputUtils.PrintSuccessMessage("The transaction was committed")
+	case teamFunctionsUtils.OutcomeDeclined:
+		outputUtils.PrintWarningMessage("The transaction was rolled back")
+	default:
+		outputUtils.PrintWarningMessage("Dry run, the transaction was rolled back - run again with --commit to commit it")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package ops
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"os"
+	"strings"
+	"time"
+)
+
+func NewImportTableCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName, table, fileName string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "import-table",
+		Short: "Import the rows of a CSV file into a table",
+		Long: `Import the rows of a CSV file into a table in a single transaction, using COPY FROM STDIN.
+The first line of the file is the header with the column names, like the files of the export-table command,
+so the columns of the file do not have to be in the order of the table, and the columns that are missing get their defaults.
+After the rows are copied their number is shown, and the transaction is committed only after the import is confirmed.
+If a row fails, for example because of a duplicate key, the transaction is rolled back and nothing is imported.
+
+Every import is appended to sqlAudit.log in the goli folder, with the user, the org and space, the database and the result.
+
+Usage:
+  goli team-features import-table -t TABLE -f FILE [OPTIONS]
+
+Options:
+  -t, --table <table>
+      The name of the table, optionally qualified with the schema, e.g. cdm.CDM_ENTITIES. The name is case-sensitive.
+      This is a required flag and must be specified.
+
+  -f, --file <file>
+      The CSV file with a header line to import.
+      This is a required flag and must be specified.
+
+  -d, --db <database>
+      The name of the database of the table.
+      If not specified, interactive mode will open to choose the database.
+
+  --timeout <duration>
+      The time the import may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features import-table -d "my_database" -t cdm.COUNTRIES -f countries.csv
+      Import countries.csv into the cdm.COUNTRIES table after confirming the number of rows.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ImportTableFun(cf, *instances, dbName, table, fileName, timeout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB of the table")
+	cmd.Flags().StringVarP(&table, "table", "t", "", "the table to import into")
+	cmd.Flags().StringVarP(&fileName, "file", "f", "", "the CSV file to import")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "", db.DefaultStatementTimeout, "the time the import may run")
+	_ = cmd.MarkFlagRequired("table")
+	_ = cmd.MarkFlagRequired("file")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ImportTableFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName, table, fileName string, timeout time.Duration) error {
+	if table == "" {
+		table = utils.StringPrompt("Enter the table to import into:")
+	}
+	if fileName == "" {
+		fileName = utils.StringPrompt("Enter the path of the CSV file:")
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	columns, err := teamFunctionsUtils.ReadCSVHeader(reader)
+	if err != nil {
+		return err
+	}
+
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+
+	outcome := teamFunctionsUtils.OutcomeCommitted
+	result, committed, err := teamFunctionsUtils.ImportTableOnInstance(cf, pgInstanceRaw, table, columns, reader, timeout, func(result *db.StatementResult) bool {
+		outputUtils.PrintWarningMessage(fmt.Sprintf("%d rows of %s were copied into %s (%s) of %s.",
+			result.RowsAffected, fileName, table, strings.Join(columns, ", "), pgInstanceRaw.Name))
+		if !utils.PresentSecurityQuestion() {
+			outcome = teamFunctionsUtils.OutcomeDeclined
+			return false
+		}
+		return true
+	})
+
+	entry := &teamFunctionsUtils.SqlAuditEntry{Db: pgInstanceRaw.Name, File: fileName, Commit: true}
+	if result != nil {
+		entry.Script = result.Statement
+		entry.Statements = []*db.StatementResult{result}
+	}
+	switch {
+	case err != nil:
+		entry.Outcome = teamFunctionsUtils.OutcomeFailed
+		entry.Error = err.Error()
+	case committed:
+		entry.Outcome = teamFunctionsUtils.OutcomeCommitted
+	default:
+		entry.Outcome = outcome
+	}
+	if auditErr := teamFunctionsUtils.AppendSqlAuditEntry(entry); auditErr != nil {
+		outputUtils.PrintWarningMessage("Failed to write the audit log:", auditErr.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	if entry.Outcome == teamFunctionsUtils.OutcomeCommitted {
+		outputUtils.PrintSuccessMessage(fmt.Sprintf("Imported %d rows into %s", result.RowsAffected, table))
+	} else {
+		outputUtils.PrintWarningMessage("The import was rolled back")
+	}
+	return nil
+}
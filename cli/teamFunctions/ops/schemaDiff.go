@@ -0,0 +1,187 @@
+package ops
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/spaceUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"strings"
+)
+
+func NewSchemaDiffCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName, againstDbName, schema string
+	var landscapeNames []string
+
+	cmd := &cobra.Command{
+		Use:   "schema-diff",
+		Short: "Compare the schema of a database across landscapes, or with another database",
+		Long: `Compare the tables, columns, indexes and constraints of a database and print a grouped report of what exists only on one side or differs.
+With --landscapes the database with the same name is compared on every landscape, using the landscapes of 'resources/landscapesInfo.json'
+and the technical user of 'config.json'. Every landscape is compared with the first one.
+With --against-db the database is compared with another database of the targeted space.
+Columns are compared by type, nullability and default, indexes and constraints by their definition.
+The columns, indexes and constraints of a table that exists on one side only are not listed separately.
+The command exits with a non-zero status if the schemas differ.
+
+Usage:
+  goli team-features schema-diff [OPTIONS]
+
+Options:
+  -d, --db <database>
+      The name of the database.
+      If not specified, interactive mode will open to choose the database.
+
+  --landscapes <landscape,...>
+      The landscapes of landscapesInfo.json to compare, at least two. The first one is the base of the comparison.
+
+  --against-db <database>
+      The database of the targeted space to compare with.
+
+  -s, --schema <schema>
+      Compare only the schema. The default is all the schemas.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features schema-diff -d "my_database" --landscapes eu10,us10
+      Compare the schema of "my_database" on eu10 and us10.
+
+  goli team-features schema-diff -d "my_database" --against-db "my_database_copy" -s cdm
+      Compare the "cdm" schema of the two databases of the targeted space.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(landscapeNames) > 0 {
+				return SchemaDiffLandscapesFun(*instances, dbName, schema, landscapeNames)
+			}
+			if againstDbName == "" {
+				return errors.New("either --landscapes or --against-db is required")
+			}
+			return SchemaDiffFun(cf, *instances, dbName, againstDbName, schema)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB to compare")
+	cmd.Flags().StringSliceVarP(&landscapeNames, "landscapes", "", nil, "the landscapes to compare the DB on")
+	cmd.Flags().StringVarP(&againstDbName, "against-db", "", "", "the DB of the targeted space to compare with")
+	cmd.Flags().StringVarP(&schema, "schema", "s", "", "compare only the schema")
+	cmd.MarkFlagsMutuallyExclusive("landscapes", "against-db")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+// SchemaDiffFun compares the schemas of two databases of the targeted space
+func SchemaDiffFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName, againstDbName, schema string) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	againstInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(againstDbName, instances)
+	if err != nil {
+		return err
+	}
+	if pgInstanceRaw.GUID == againstInstanceRaw.GUID {
+		return errors.New("cannot compare a database with itself")
+	}
+
+	var snapshots []*teamFunctionsUtils.SchemaSnapshot
+	for _, instance := range []*entities.Instance{pgInstanceRaw, againstInstanceRaw} {
+		results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, instance, db.DefaultStatementTimeout, teamFunctionsUtils.SchemaSnapshotQueries(schema)...)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, teamFunctionsUtils.NewSchemaSnapshot(instance.Name, results))
+	}
+	return printSchemaDiffs(snapshots)
+}
+
+// SchemaDiffLandscapesFun compares the schema of the database with the name on the landscapes
+func SchemaDiffLandscapesFun(instances *map[string][]*entities.Instance, dbName, schema string, landscapeNames []string) error {
+	if len(landscapeNames) < 2 {
+		return errors.New("at least two landscapes are required")
+	}
+	allLandscapes, err := teamFunctionsUtils.LoadLandscapes()
+	if err != nil {
+		return err
+	}
+	landscapes, err := teamFunctionsUtils.SelectLandscapes(allLandscapes, landscapeNames)
+	if err != nil {
+		return err
+	}
+	if dbName == "" {
+		pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance("", instances)
+		if err != nil {
+			return err
+		}
+		dbName = pgInstanceRaw.Name
+	}
+
+	var snapshots []*teamFunctionsUtils.SchemaSnapshot
+	for _, landscape := range landscapes {
+		fmt.Println("reading the schema of", color.HiCyanString(dbName), "on", landscape.Name+"...")
+		cf, pgInstance, err := landscape.PostgresInstance(dbName)
+		if err != nil {
+			return err
+		}
+		results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstance, db.DefaultStatementTimeout, teamFunctionsUtils.SchemaSnapshotQueries(schema)...)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, teamFunctionsUtils.NewSchemaSnapshot(landscape.Name, results))
+	}
+	return printSchemaDiffs(snapshots)
+}
+
+// SchemaDiffInteractive asks whether to compare with another database or across landscapes
+func SchemaDiffInteractive(cf *client.Client, instances *map[string][]*entities.Instance) error {
+	const againstDb = "Compare with another DB of this space"
+	const acrossLandscapes = "Compare across landscapes"
+	mode, _ := utils.ListAndSelectItem([]string{againstDb, acrossLandscapes}, "select a comparison:", false)
+	if mode == acrossLandscapes {
+		landscapeNames := strings.Split(utils.StringPrompt("Enter the landscapes to compare, separated by commas:"), ",")
+		for i := range landscapeNames {
+			landscapeNames[i] = strings.TrimSpace(landscapeNames[i])
+		}
+		return SchemaDiffLandscapesFun(instances, "", "", landscapeNames)
+	}
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance("", instances)
+	if err != nil {
+		return err
+	}
+	fmt.Println("select the DB to compare with")
+	againstInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance("", instances)
+	if err != nil {
+		return err
+	}
+	return SchemaDiffFun(cf, instances, pgInstanceRaw.Name, againstInstanceRaw.Name, "")
+}
+
+// printSchemaDiffs compares every snapshot with the first one
+func printSchemaDiffs(snapshots []*teamFunctionsUtils.SchemaSnapshot) error {
+	base := snapshots[0]
+	total := 0
+	for _, snapshot := range snapshots[1:] {
+		fmt.Println()
+		fmt.Println("comparing", color.HiCyanString(base.Label), "with", color.HiCyanString(snapshot.Label))
+		differences := spaceUtils.PrintSpaceDiff(teamFunctionsUtils.DiffSchemas(base, snapshot), base.Label, snapshot.Label)
+		if differences == 0 {
+			outputUtils.PrintSuccessMessage("The schemas of", base.Label, "and", snapshot.Label, "are identical")
+		}
+		total += differences
+	}
+	fmt.Println()
+	if total > 0 {
+		return fmt.Errorf("the schemas have %d differences", total)
+	}
+	outputUtils.PrintSuccessMessage("The schemas are identical")
+	return nil
+}
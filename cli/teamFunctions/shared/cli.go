@@ -3,23 +3,31 @@ package shared
 import (
 	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"goli-cli/db"
 	"goli-cli/entities"
 	. "goli-cli/types"
 	"goli-cli/utils"
 	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
 )
 
 const (
-	RunQuery    = "Run query on the DB"
-	ConnectToDB = "Connect to DB"
-	Back        = "Back"
+	RunQuery      = "Run query on the DB"
+	RunSavedQuery = "Run a saved query"
+	ExplainQuery  = "Explain a query"
+	BrowseSchema  = "Browse the DB schema"
+	DbHealth      = "Show the DB health report"
+	DbSessions    = "List the DB sessions"
+	ExportTable   = "Export a table to CSV"
+	ConnectToDB   = "Connect to DB"
+	Back          = "Back"
 )
 
-var sharedOptions = []string{RunQuery, ConnectToDB}
+var sharedOptions = []string{RunQuery, RunSavedQuery, ExplainQuery, BrowseSchema, DbHealth, DbSessions, ExportTable, ConnectToDB}
 
 func SharedCli(cf *client.Client, apps *map[string]AppData, instances *map[string][]*entities.Instance, names *[]string) {
 	var err error
-	teamOptions := []string{RunQuery, ConnectToDB, Back}
+	teamOptions := []string{RunQuery, RunSavedQuery, ExplainQuery, BrowseSchema, DbHealth, DbSessions, ExportTable, ConnectToDB, Back}
 
 	for {
 		option, _ := utils.ListAndSelectItem(teamOptions, "select an option:", false)
@@ -43,11 +51,33 @@ func ExecuteOption(cf *client.Client, option string, instances *map[string][]*en
 	switch option {
 	case RunQuery:
 		fmt.Println("Running query on DB")
-		err = RunQueryFun(cf, instances, "", "", "")
+		err = RunQueryFun(cf, instances, "", "", "", "", db.DefaultStatementTimeout, db.StreamOptions{PageSize: teamFunctionsUtils.DefaultPageSize})
+		return true, err
+	case RunSavedQuery:
+		err = RunSavedQueryInteractive(cf, instances)
+		return true, err
+	case ExplainQuery:
+		fmt.Println("Explaining query")
+		analyze := utils.StringPrompt("Execute the query to show the actual rows and times? (y/n)") == "y"
+		err = ExplainFun(cf, instances, "", "", analyze, false, db.DefaultStatementTimeout)
+		return true, err
+	case BrowseSchema:
+		table := utils.StringPrompt("Enter the table to describe, or leave empty to list the tables:")
+		err = SchemaFun(cf, instances, "", "", table)
+		return true, err
+	case DbHealth:
+		err = DbHealthFun(cf, instances, "", defaultLongQuery)
+		return true, err
+	case DbSessions:
+		err = ListDbSessionsFun(cf, instances, "", false)
+		return true, err
+	case ExportTable:
+		fileName := utils.StringPrompt("Enter the CSV file to export to:")
+		err = ExportTableFun(cf, instances, "", "", "", fileName, db.DefaultStatementTimeout)
 		return true, err
 	case ConnectToDB:
 		fmt.Println("Connecting to DB")
-		err = ConnectToDbCmdFunc(cf, instances, "")
+		err = ConnectToDbCmdFunc(cf, instances, "", 0, false)
 		return true, err
 	default:
 		return false, nil
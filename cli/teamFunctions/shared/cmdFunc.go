@@ -1,22 +1,32 @@
 package shared
 
 import (
-	"errors"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"github.com/spf13/cobra"
+	"goli-cli/db"
 	"goli-cli/entities"
 	"goli-cli/utils"
 	"goli-cli/utils/teamFunctionsUtils"
+	"time"
 )
 
 func NewRunQueryCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
-	var query, dbName, fileName string
+	var query, dbName, fileName, format, savedName string
+	var params []string
+	var timeout time.Duration
+	var limit, pageSize int
 
 	cmd := &cobra.Command{
 		Use:   "run-query",
 		Short: "Run a direct query on the database",
 		Long: `Run a direct query on the database without the need to open it or interact with the database manually.
-This command allows users to execute SQL queries (SELECT query only) directly from the CLI to retrieve data in the database.
+This command allows users to execute read-only SQL queries directly from the CLI to retrieve data in the database.
+Only SELECT, WITH, VALUES and TABLE queries are accepted. The query runs in a read-only transaction, so the database rejects any statement that writes,
+and it is canceled when it exceeds the timeout.
+Functions that a SELECT can call in a read-only transaction, such as pg_terminate_backend, are limited only by the privileges of the database user.
+Blocking them needs a database user without the pg_signal_backend role, which can still signal the sessions of the same user.
+The rows are streamed to the terminal or the file while they are read, so large results do not have to fit in memory,
+and the number of rows and the time of the query are shown at the end.
 
 Usage:
   goli team-features run-query [OPTIONS]
@@ -28,15 +38,44 @@ Options:
   -q, --query <query>  
       The SQL query you wish to execute on the database.
       If not specified, an interactive mode will open to input the query.
+
+  -s, --saved <name>
+      Run a query from the saved queries library instead of --query, see 'goli team-features saved-queries --help'.
+
+  -p, --param <key=value>
+      The value of a :key parameter of the saved query. Can be specified multiple times.
+
+  -t, --timeout <duration>
+      The time the query may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+      The rows are read through a cursor, and the timeout applies to every page of rows.
+
+  -l, --limit <rows>
+      The maximum number of rows to read. If the query has more rows, only the first rows are shown.
+
+  --page-size <rows>
+      The number of rows of every table page printed in the terminal. The default is 50.
+      In an interactive terminal the next page is printed after pressing Enter, and q stops the query.
+      Use 0 to print all the rows in one table.
   
   -d, --db <database>  
       The name of the database where the query will be executed.
-      If not specified, an interactive mode will open to choose the database.
+      If not specified, the database of the saved query is used, or an interactive mode will open to choose the database.
 
   -f, --file <file>
       The name of the file to save the query result to.
       If not specified, the query result will be displayed in the terminal.
 
+  --format <format>
+      The format of the query result, one of:
+        table       A table, can only be printed. This is the default when no file is specified.
+        csv         Comma-separated values, NULL is written as NULL. This is the default when saving to a file.
+        json        An array of objects, one for each row.
+        ndjson      One JSON object per line.
+        markdown    A markdown table.
+        xlsx        An Excel workbook, can only be saved to a file.
+      In the JSON formats numbers and booleans keep their type, NULL is null and json columns are nested.
+      When saving to a file without a format, the format is taken from the file extension.
+
   -h, --help  
       Display this help message and exit.
   
@@ -47,18 +86,40 @@ Examples:
   goli team-features query -q "SELECT * FROM users" -f "users.csv"
       Execute the query "SELECT * FROM users" and save the result to the "users.csv" file.
 
+  goli team-features query -q "SELECT * FROM events ORDER BY created DESC" --limit 10
+      Show the 10 latest events.
+
+  goli team-features query -q "SELECT * FROM users" --format json
+      Execute the query "SELECT * FROM users" and print the result as JSON.
+
+  goli team-features query -q "SELECT * FROM users" -f users.xlsx
+      Execute the query "SELECT * FROM users" and save the result to an Excel workbook.
+
+  goli team-features query --saved tenant-entities --param tenant=my-tenant
+      Run the saved query "tenant-entities" with the :tenant parameter set to "my-tenant".
+
   goli team-features query  
       Open interactive mode to input the query and select the database.`,
 		Aliases: []string{"query"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunQueryFun(cf, *instances, query, dbName, fileName)
+			if savedName != "" {
+				return RunSavedQueryFun(cf, *instances, savedName, params, dbName, format, fileName, timeout, db.StreamOptions{Limit: limit, PageSize: pageSize})
+			}
+			return RunQueryFun(cf, *instances, query, dbName, format, fileName, timeout, db.StreamOptions{Limit: limit, PageSize: pageSize})
 		},
 	}
 
 	cmd.Flags().StringVarP(&query, "query", "q", "", "query to run on the DB")
 	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB on which the query will be run on")
 	cmd.Flags().StringVarP(&fileName, "fileName", "f", "", "the name of the file to save the query result to")
+	cmd.Flags().StringVarP(&format, "format", "", "", "the format of the query result")
+	cmd.Flags().StringVarP(&savedName, "saved", "s", "", "the name of the saved query to run")
+	cmd.Flags().StringArrayVarP(&params, "param", "p", nil, "a parameter of the saved query in the key=value form")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", db.DefaultStatementTimeout, "the time the query may run")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 0, "the maximum number of rows to read")
+	cmd.Flags().IntVarP(&pageSize, "page-size", "", teamFunctionsUtils.DefaultPageSize, "the number of rows of every table page")
+	cmd.MarkFlagsMutuallyExclusive("query", "saved")
 
 	cmd.SetHelpTemplate(cmd.Long)
 
@@ -66,6 +127,8 @@ Examples:
 }
 func NewConnectToDbCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
 	var dbName string
+	var localPort int
+	var console bool
 
 	cmd := &cobra.Command{
 		Use:   "connect-to-db",
@@ -83,46 +146,72 @@ Options:
       The name of the database 
       If not specified, interactive mode will open to choose the database.
 
+  -p, --local-port <port>
+      The local port to open the tunnel on.
+      If not specified, port 5432 is used, or a free port when 5432 is occupied.
+
+  -c, --console
+      Open the built-in SQL console instead of the DB client.
+      The console supports multi-line queries ending with ';', history, and the commands
+      \dt (list tables), \d TABLE (describe a table), \x (expanded display), \timing and \q (quit).
+
   -h, --help              
       Display this help message and exit.  
 
+Clients:
+  The connection is opened in TablePlus, or in the client set in the "dbClients" section of config.json,
+  see 'goli applications postgres --help' for the supported clients.
+
 Examples:
   goli connect-to-db
       list all the postgresql databases and connect to the selected one.
 
   goli connect-to-db --db-name custom-db-name
-      Connect to the PostgreSQL database with the explicitly specified name "custom-db-name".`,
+      Connect to the PostgreSQL database with the explicitly specified name "custom-db-name".
+
+  goli connect-to-db --db custom-db-name --local-port 15432
+      Connect to "custom-db-name" through local port 15432.
+
+  goli connect-to-db --db custom-db-name --console
+      Query "custom-db-name" in the built-in SQL console.`,
 		Aliases: []string{"ctd"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ConnectToDbCmdFunc(cf, *instances, dbName)
+			return ConnectToDbCmdFunc(cf, *instances, dbName, localPort, console)
 		},
 	}
 
 	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB on which a connection will be established")
+	cmd.Flags().IntVarP(&localPort, "local-port", "p", 0, "the local port to open the tunnel on")
+	cmd.Flags().BoolVarP(&console, "console", "c", false, "open the built-in SQL console")
 
 	cmd.SetHelpTemplate(cmd.Long)
 
 	return cmd
 }
 
-func ConnectToDbCmdFunc(cf *client.Client, instances *map[string][]*entities.Instance, db string) error {
+func ConnectToDbCmdFunc(cf *client.Client, instances *map[string][]*entities.Instance, db string, localPort int, console bool) error {
 	var pgInstanceRaw *entities.Instance
 	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(db, instances)
 	if err != nil {
 		return err
 	}
-	err = teamFunctionsUtils.ConnectToDB(cf, instances, pgInstanceRaw)
+	err = teamFunctionsUtils.ConnectToDB(cf, instances, pgInstanceRaw, localPort, console)
 	if err != nil {
 		return err
 	}
 	return nil
 }
-func RunQueryFun(cf *client.Client, instances *map[string][]*entities.Instance, queryInput, db, fileName string) error {
+func RunQueryFun(cf *client.Client, instances *map[string][]*entities.Instance, queryInput, dbName, format, fileName string, timeout time.Duration, options db.StreamOptions) error {
 	var query string
 	var pgInstanceRaw *entities.Instance
 
-	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(db, instances)
+	format, fileName, err := teamFunctionsUtils.ResolveQueryFormat(format, fileName)
+	if err != nil {
+		return err
+	}
+
+	pgInstanceRaw, err = teamFunctionsUtils.GetPostgresInstance(dbName, instances)
 	if err != nil {
 		return err
 	}
@@ -132,11 +221,7 @@ func RunQueryFun(cf *client.Client, instances *map[string][]*entities.Instance,
 	} else {
 		query = utils.StringPrompt("Enter the query:")
 	}
-	if !teamFunctionsUtils.ValidateQuery(query) {
-		return errors.New("invalid query - query be a 'select' query and contain 'from' clauses")
-	}
-
-	err = teamFunctionsUtils.ConnectAndPrintQuery(cf, pgInstanceRaw, query, fileName)
+	err = teamFunctionsUtils.ConnectAndPrintQuery(cf, pgInstanceRaw, query, format, fileName, timeout, options)
 	if err != nil {
 		return err
 	}
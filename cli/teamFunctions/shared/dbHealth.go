@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"time"
+)
+
+// the queries that run longer than this are reported when no other duration is specified
+const defaultLongQuery = time.Minute
+
+func NewDbHealthCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName string
+	var longQuery time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "db-health",
+		Short: "Show a health report of the database",
+		Long: `Show a health report of the database, with warnings for the findings that need attention.
+The report has the following sections:
+  - the active, idle and idle in transaction connections compared to max_connections
+  - the queries that are running for a long time
+  - the sessions that are blocked by the locks of other sessions, with the blocking session and the lock
+  - the tables with many dead tuples, which autovacuum does not keep up with
+  - the largest tables and indexes
+  - the indexes that were never scanned
+  - the cache hit ratio of the tables and indexes
+  - the statements with the longest total time, if the pg_stat_statements extension is installed
+The sessions of other database users are only shown to users with the pg_read_all_stats role.
+
+Usage:
+  goli team-features db-health [OPTIONS]
+
+Options:
+  -d, --db <database>
+      The name of the database.
+      If not specified, interactive mode will open to choose the database.
+
+  --long-query <duration>
+      The time after which a running query is reported, e.g. 30s or 5m. The default is 1m.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features db-health -d "my_database"
+      Show the health report of the "my_database" database.
+
+  goli team-features db-health -d "my_database" --long-query 10s
+      Show the health report, with the queries that are running for longer than 10 seconds.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return DbHealthFun(cf, *instances, dbName, longQuery)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB to report on")
+	cmd.Flags().DurationVarP(&longQuery, "long-query", "", defaultLongQuery, "the time after which a running query is reported")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func DbHealthFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName string, longQuery time.Duration) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.HealthQueries(longQuery)...)
+	if err != nil {
+		return err
+	}
+
+	warnings := teamFunctionsUtils.PrintHealthReport(results, longQuery)
+	fmt.Println()
+	if warnings > 0 {
+		outputUtils.PrintErrorMessage(fmt.Sprintf("%d warnings", warnings))
+	} else {
+		outputUtils.PrintSuccessMessage("No warnings")
+	}
+	return nil
+}
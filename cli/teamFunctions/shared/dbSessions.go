@@ -0,0 +1,170 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"strconv"
+)
+
+// NewDbSessionsCmd creates the db-sessions command, the sessions can only be canceled and terminated if canSignal is set
+func NewDbSessionsCmd(cf *client.Client, instances **map[string][]*entities.Instance, canSignal bool) *cobra.Command {
+	var dbName string
+	var activeOnly bool
+	var cancelPid, terminatePid int
+
+	cmd := &cobra.Command{
+		Use:   "db-sessions",
+		Short: "List the sessions of the database, and cancel or terminate them",
+		Long: `List the client sessions of the database with their user, application, state, duration and current query.
+With --cancel the current query of a session is canceled, and with --terminate the whole session is closed, which also rolls back its transaction.
+The --cancel and --terminate options are available only to the ops role, and require a confirmation.
+Only these options are gated: the database user can still call pg_cancel_backend and pg_terminate_backend with any SQL,
+e.g. in the console of connect-to-db or in a run-query SELECT, so a role cannot be prevented from signalling sessions by this tool.
+The sessions of other database users are only shown to users with the pg_read_all_stats role.
+
+Usage:
+  goli team-features db-sessions [OPTIONS]
+
+Options:
+  -d, --db <database>
+      The name of the database.
+      If not specified, interactive mode will open to choose the database.
+
+  -a, --active
+      List only the sessions that are not idle.
+
+  --cancel <pid>
+      Cancel the current query of the session with the pid, using pg_cancel_backend.
+
+  --terminate <pid>
+      Terminate the session with the pid, using pg_terminate_backend.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features db-sessions -d "my_database" --active
+      List the sessions of "my_database" that are running a query or are in a transaction.
+
+  goli team-features db-sessions -d "my_database" --cancel 12345
+      Cancel the query of the session with pid 12345.
+
+  goli team-features db-sessions -d "my_database" --terminate 12345
+      Terminate the session with pid 12345.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cancelPid == 0 && terminatePid == 0 {
+				return ListDbSessionsFun(cf, *instances, dbName, activeOnly)
+			}
+			if !canSignal {
+				return errors.New("the --cancel and --terminate options are available only to the ops role")
+			}
+			if terminatePid != 0 {
+				return SignalDbSessionFun(cf, *instances, dbName, terminatePid, true)
+			}
+			return SignalDbSessionFun(cf, *instances, dbName, cancelPid, false)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB of the sessions")
+	cmd.Flags().BoolVarP(&activeOnly, "active", "a", false, "list only the sessions that are not idle")
+	cmd.Flags().IntVarP(&cancelPid, "cancel", "", 0, "the pid of the session to cancel the query of")
+	cmd.Flags().IntVarP(&terminatePid, "terminate", "", 0, "the pid of the session to terminate")
+	cmd.MarkFlagsMutuallyExclusive("cancel", "terminate")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ListDbSessionsFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName string, activeOnly bool) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.SessionsQuery(activeOnly))
+	if err != nil {
+		return err
+	}
+	if len(results[0].Rows) == 0 {
+		outputUtils.PrintWarningMessage("There are no sessions")
+		return nil
+	}
+	teamFunctionsUtils.PrintCompactTable(results[0])
+	return nil
+}
+
+// SignalDbSessionFun shows the session and cancels its query or terminates it after a confirmation
+func SignalDbSessionFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName string, pid int, terminate bool) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.SessionQuery(pid))
+	if err != nil {
+		return err
+	}
+	if len(results[0].Rows) == 0 {
+		return fmt.Errorf("there is no client session with pid %d", pid)
+	}
+	teamFunctionsUtils.PrintCompactTable(results[0])
+	return signalDbSession(cf, pgInstanceRaw, pid, terminate)
+}
+
+// SignalDbSessionInteractive lists the sessions and cancels or terminates the selected one
+func SignalDbSessionInteractive(cf *client.Client, instances *map[string][]*entities.Instance) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance("", instances)
+	if err != nil {
+		return err
+	}
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.SessionsQuery(false))
+	if err != nil {
+		return err
+	}
+	if len(results[0].Rows) == 0 {
+		return errors.New("there are no sessions")
+	}
+
+	var sessions []string
+	for _, row := range results[0].Rows {
+		// pid, user, application, client, state, duration, waiting, query
+		sessions = append(sessions, fmt.Sprintf("%v %v (%v, %v %v) %v", row[0], row[1], row[2], row[4], row[5], row[7]))
+	}
+	_, index := utils.ListAndSelectItem(sessions, "select a session:", false)
+	pid, err := strconv.Atoi(db.FormatValue(results[0].Rows[index][0]))
+	if err != nil {
+		return err
+	}
+	action, _ := utils.ListAndSelectItem([]string{"Cancel the query", "Terminate the session"}, "select an action:", false)
+	return signalDbSession(cf, pgInstanceRaw, pid, action == "Terminate the session")
+}
+
+func signalDbSession(cf *client.Client, pgInstanceRaw *entities.Instance, pid int, terminate bool) error {
+	action := "canceled the query of"
+	if terminate {
+		action = "terminated"
+		outputUtils.PrintWarningMessage(fmt.Sprintf("The session %d will be terminated and its transaction rolled back.", pid))
+	} else {
+		outputUtils.PrintWarningMessage(fmt.Sprintf("The current query of the session %d will be canceled.", pid))
+	}
+	if !utils.PresentSecurityQuestion() {
+		return nil
+	}
+
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.SignalSessionQuery(pid, terminate))
+	if err != nil {
+		return err
+	}
+	if signaled, _ := results[0].Rows[0][0].(bool); !signaled {
+		return fmt.Errorf("the session %d could not be signaled, it may have ended already", pid)
+	}
+	outputUtils.PrintSuccessMessage(fmt.Sprintf("Successfully %s the session %d", action, pid))
+	return nil
+}
@@ -0,0 +1,101 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"time"
+)
+
+func NewExplainCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var query, dbName string
+	var analyze, raw bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Show the execution plan of a query",
+		Long: `Show the execution plan of a query on the database as an indented tree, with the estimated cost and rows of every step.
+With --analyze the query is executed to show the actual rows, times and buffers, in a read-only transaction that is always rolled back.
+Sequential scans of more than 10000 rows and row estimates that are off by 10x or more are highlighted.
+
+Usage:
+  goli team-features explain [OPTIONS]
+
+Options:
+  -q, --query <query>
+      The SQL query to explain, a SELECT, WITH, VALUES or TABLE query.
+      If not specified, an interactive mode will open to input the query.
+
+  -d, --db <database>
+      The name of the database where the query will be explained.
+      If not specified, interactive mode will open to choose the database.
+
+  -a, --analyze
+      Execute the query and show the actual rows, times and buffers of every step.
+      The query runs in a read-only transaction, so queries that write, e.g. data-modifying CTEs, are refused.
+
+  -t, --timeout <duration>
+      The time the explained query may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+
+  --json
+      Print the plan as the JSON of EXPLAIN (FORMAT JSON), e.g. for plan visualizers.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features explain -d "my_database" -q "SELECT * FROM users WHERE email = 'a@b.c'"
+      Show the estimated plan of the query.
+
+  goli team-features explain -d "my_database" -q "SELECT * FROM users WHERE email = 'a@b.c'" --analyze
+      Execute the query and show the plan with the actual rows and times.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ExplainFun(cf, *instances, query, dbName, analyze, raw, timeout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&query, "query", "q", "", "the query to explain")
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB on which the query will be explained")
+	cmd.Flags().BoolVarP(&analyze, "analyze", "a", false, "execute the query and show the actual rows and times")
+	cmd.Flags().BoolVarP(&raw, "json", "", false, "print the plan as JSON")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", db.DefaultStatementTimeout, "the time the explained query may run")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ExplainFun(cf *client.Client, instances *map[string][]*entities.Instance, query, dbName string, analyze, raw bool, timeout time.Duration) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	if query == "" {
+		query = utils.StringPrompt("Enter the query:")
+	}
+
+	planJson, err := teamFunctionsUtils.ExplainOnInstance(cf, pgInstanceRaw, query, analyze, timeout)
+	if err != nil {
+		return err
+	}
+	if raw {
+		fmt.Println(planJson)
+		return nil
+	}
+
+	plan, err := teamFunctionsUtils.ParseQueryPlan(planJson)
+	if err != nil {
+		return err
+	}
+	if highlights := teamFunctionsUtils.PrintQueryPlan(plan); highlights > 0 {
+		outputUtils.PrintWarningMessage(fmt.Sprintf("%d steps of the plan are highlighted", highlights))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"io"
+	"os"
+	"time"
+)
+
+func NewExportTableCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName, table, where, fileName string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "export-table",
+		Short: "Export the rows of a table to a CSV file",
+		Long: `Export the rows of a table as CSV with a header, using COPY TO STDOUT in a read-only transaction.
+The rows are written while they are read, so tables that do not fit in memory can be exported.
+The file can be imported into the table of another landscape with the import-table command.
+
+Usage:
+  goli team-features export-table -t TABLE [OPTIONS]
+
+Options:
+  -t, --table <table>
+      The name of the table, optionally qualified with the schema, e.g. cdm.CDM_ENTITIES. The name is case-sensitive.
+      This is a required flag and must be specified.
+
+  -d, --db <database>
+      The name of the database of the table.
+      If not specified, interactive mode will open to choose the database.
+
+  --where <condition>
+      Export only the rows that match the condition. The condition is raw SQL that is put after WHERE as it is,
+      it must be a single expression and runs in the read-only transaction of the export.
+
+  -o, --output <file>
+      The CSV file to write the rows to. If not specified, the rows are written to the standard output.
+
+  --timeout <duration>
+      The time the export may run before the database cancels it, e.g. 30s or 5m. The default is 60s.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features export-table -d "my_database" -t cdm.COUNTRIES -o countries.csv
+      Export the rows of the cdm.COUNTRIES table to countries.csv.
+
+  goli team-features export-table -d "my_database" -t cdm.COUNTRIES --where "region = 'EU'" -o eu.csv
+      Export the rows of the EU region only.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ExportTableFun(cf, *instances, dbName, table, where, fileName, timeout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB of the table")
+	cmd.Flags().StringVarP(&table, "table", "t", "", "the table to export")
+	cmd.Flags().StringVarP(&where, "where", "", "", "export only the rows that match the condition")
+	cmd.Flags().StringVarP(&fileName, "output", "o", "", "the CSV file to write the rows to")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "", db.DefaultStatementTimeout, "the time the export may run")
+	_ = cmd.MarkFlagRequired("table")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ExportTableFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName, table, where, fileName string, timeout time.Duration) error {
+	if table == "" {
+		table = utils.StringPrompt("Enter the table to export:")
+	}
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stdout
+	var file *os.File
+	if fileName != "" {
+		file, err = os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		writer = file
+	}
+	rows, err := teamFunctionsUtils.ExportTableOnInstance(cf, pgInstanceRaw, table, where, timeout, writer)
+	if err != nil {
+		if file != nil {
+			// a partial file would be imported as if it had all the rows
+			file.Close()
+			os.Remove(fileName)
+		}
+		return err
+	}
+	// printed to stderr, so the rows can be piped
+	if fileName != "" {
+		fmt.Fprintf(os.Stderr, "%d rows exported to %s\n", rows, fileName)
+	} else {
+		fmt.Fprintf(os.Stderr, "%d rows exported\n", rows)
+	}
+	return nil
+}
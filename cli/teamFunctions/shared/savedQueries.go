@@ -0,0 +1,274 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+	"strings"
+	"time"
+)
+
+func NewSavedQueriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "saved-queries",
+		Aliases: []string{"sq"},
+		Short:   "Manage the library of saved queries",
+		Long: `Manage the library of saved queries, which are run with 'goli team-features run-query --saved NAME'.
+The queries are stored in savedQueries.yml in the goli folder, with a name, a description, the SQL and the database they are run on.
+The SQL may contain :name parameters, e.g. "SELECT * FROM jobs WHERE tenant = :tenant", which are set with --param name=value when running the query.
+The parameter values are sent separately from the SQL, so they do not need to be quoted.
+
+Usage:
+  goli team-features saved-queries COMMAND
+
+Aliases:
+  saved-queries, sq
+
+Commands:
+  list              List the saved queries.
+  add NAME          Save a query.
+  remove NAME       Remove a saved query.
+
+Examples:
+  goli team-features saved-queries add stuck-jobs -q "SELECT * FROM jobs WHERE status = 'RUNNING' AND tenant = :tenant" -d jobs-db
+      Save a query with the :tenant parameter that is run on the "jobs-db" database.
+
+  goli team-features query --saved stuck-jobs --param tenant=my-tenant
+      Run the saved query for the tenant "my-tenant".`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newListSavedQueriesCmd(), newAddSavedQueryCmd(), newRemoveSavedQueryCmd())
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func newListSavedQueriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the saved queries",
+		Long: `List the saved queries with their database, parameters and description.
+
+Usage:
+  goli team-features saved-queries list
+
+Aliases:
+  list, ls`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ListSavedQueries()
+		},
+	}
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func newAddSavedQueryCmd() *cobra.Command {
+	var query, dbName, description string
+
+	cmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "Save a query",
+		Long: `Save a query in the library under the given name.
+Like the queries of run-query, the saved queries run in a read-only transaction.
+
+Usage:
+  goli team-features saved-queries add NAME [OPTIONS]
+
+Arguments:
+  NAME
+      The name of the saved query.
+      This is a required argument and must be specified before any options.
+
+Options:
+  -q, --query <query>
+      The SQL of the query, which may contain :name parameters.
+      If not specified, an interactive mode will open to input the query.
+
+  -d, --db <database>
+      The name of the database the query is run on when no database is specified.
+
+  --description <description>
+      A description of the query, shown in the list of saved queries.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features saved-queries add tenant-entities -q "SELECT count(*) FROM entities WHERE tenant = :tenant" --description "number of entities of a tenant"
+      Save a query for counting the entities of a tenant.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return AddSavedQuery(args[0], query, dbName, description)
+		},
+	}
+
+	cmd.Flags().StringVarP(&query, "query", "q", "", "the SQL of the query")
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB the query is run on")
+	cmd.Flags().StringVarP(&description, "description", "", "", "the description of the query")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func newRemoveSavedQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "Remove a saved query",
+		Long: `Remove a query from the library of saved queries.
+
+Usage:
+  goli team-features saved-queries remove NAME
+
+Aliases:
+  remove, rm
+
+Arguments:
+  NAME
+      The name of the saved query.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RemoveSavedQuery(args[0])
+		},
+	}
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func ListSavedQueries() error {
+	queries, err := teamFunctionsUtils.LoadSavedQueries()
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		outputUtils.PrintWarningMessage("There are no saved queries, add one with 'goli team-features saved-queries add'")
+		return nil
+	}
+	teamFunctionsUtils.PrintSavedQueries(queries)
+	return nil
+}
+
+func AddSavedQuery(name, query, dbName, description string) error {
+	queries, err := teamFunctionsUtils.LoadSavedQueries()
+	if err != nil {
+		return err
+	}
+	if _, err = teamFunctionsUtils.FindSavedQuery(queries, name); err == nil {
+		return fmt.Errorf("saved query %s already exists, remove it first to replace it", name)
+	}
+	if query == "" {
+		query = utils.StringPrompt("Enter the query:")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return errors.New("the query must not be empty")
+	}
+
+	queries = append(queries, &teamFunctionsUtils.SavedQuery{Name: name, Description: description, SQL: query, Db: dbName})
+	err = teamFunctionsUtils.StoreSavedQueries(queries)
+	if err != nil {
+		return err
+	}
+	outputUtils.PrintSuccessMessage("Saved the query", name)
+	return nil
+}
+
+func RemoveSavedQuery(name string) error {
+	queries, err := teamFunctionsUtils.LoadSavedQueries()
+	if err != nil {
+		return err
+	}
+	query, err := teamFunctionsUtils.FindSavedQuery(queries, name)
+	if err != nil {
+		return err
+	}
+	var remaining []*teamFunctionsUtils.SavedQuery
+	for _, savedQuery := range queries {
+		if savedQuery != query {
+			remaining = append(remaining, savedQuery)
+		}
+	}
+	err = teamFunctionsUtils.StoreSavedQueries(remaining)
+	if err != nil {
+		return err
+	}
+	outputUtils.PrintSuccessMessage("Removed the saved query", name)
+	return nil
+}
+
+func RunSavedQueryFun(cf *client.Client, instances *map[string][]*entities.Instance, name string, rawParams []string, dbName, format, fileName string, timeout time.Duration, options db.StreamOptions) error {
+	params, err := teamFunctionsUtils.ParseQueryParams(rawParams)
+	if err != nil {
+		return err
+	}
+	queries, err := teamFunctionsUtils.LoadSavedQueries()
+	if err != nil {
+		return err
+	}
+	savedQuery, err := teamFunctionsUtils.FindSavedQuery(queries, name)
+	if err != nil {
+		return err
+	}
+	return runSavedQuery(cf, instances, savedQuery, params, dbName, format, fileName, timeout, options)
+}
+
+func runSavedQuery(cf *client.Client, instances *map[string][]*entities.Instance, savedQuery *teamFunctionsUtils.SavedQuery, params map[string]string, dbName, format, fileName string, timeout time.Duration, options db.StreamOptions) error {
+	format, fileName, err := teamFunctionsUtils.ResolveQueryFormat(format, fileName)
+	if err != nil {
+		return err
+	}
+	query, args, err := teamFunctionsUtils.BindQueryParams(savedQuery.SQL, params)
+	if err != nil {
+		return err
+	}
+
+	if dbName == "" {
+		dbName = savedQuery.Db
+	}
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+	return teamFunctionsUtils.ConnectAndPrintQuery(cf, pgInstanceRaw, query, format, fileName, timeout, options, args...)
+}
+
+func RunSavedQueryInteractive(cf *client.Client, instances *map[string][]*entities.Instance) error {
+	queries, err := teamFunctionsUtils.LoadSavedQueries()
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return errors.New("there are no saved queries, add one with 'goli team-features saved-queries add'")
+	}
+	var names []string
+	for _, query := range queries {
+		label := query.Name
+		if query.Description != "" {
+			label += " - " + query.Description
+		}
+		names = append(names, label)
+	}
+	_, index := utils.ListAndSelectItem(names, "select a query:", false)
+	savedQuery := queries[index]
+
+	params := make(map[string]string)
+	for _, param := range teamFunctionsUtils.QueryParamNames(savedQuery.SQL) {
+		params[param] = utils.StringPrompt(fmt.Sprintf("Enter the value of :%s:", param))
+	}
+	return runSavedQuery(cf, instances, savedQuery, params, "", "", "", db.DefaultStatementTimeout, db.StreamOptions{PageSize: teamFunctionsUtils.DefaultPageSize})
+}
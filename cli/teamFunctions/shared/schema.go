@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/spf13/cobra"
+	"goli-cli/db"
+	"goli-cli/entities"
+	"goli-cli/utils/outputUtils"
+	"goli-cli/utils/teamFunctionsUtils"
+)
+
+func NewSchemaCmd(cf *client.Client, instances **map[string][]*entities.Instance) *cobra.Command {
+	var dbName, schema string
+
+	cmd := &cobra.Command{
+		Use:   "schema [TABLE]",
+		Short: "Browse the schemas and tables of the database",
+		Long: `Browse the schemas and tables of the database without opening a DB client.
+Without a table the command lists the tables, views and materialized views of all the schemas, with their row estimates and sizes.
+With a table it shows the columns with their types, nullability and defaults, the indexes, the constraints, the foreign keys
+and the foreign keys of other tables that reference it.
+
+Usage:
+  goli team-features schema [TABLE] [OPTIONS]
+
+Arguments:
+  TABLE
+      The name of the table, optionally qualified with the schema, e.g. cdm.CDM_ENTITIES.
+      The name is matched case-insensitively if there is no table with the exact name.
+
+Options:
+  -d, --db <database>
+      The name of the database to browse.
+      If not specified, interactive mode will open to choose the database.
+
+  -s, --schema <schema>
+      List only the tables of the schema.
+
+  -h, --help
+      Display this help message and exit.
+
+Examples:
+  goli team-features schema -d "my_database"
+      List the tables of all the schemas of "my_database".
+
+  goli team-features schema -d "my_database" -s cdm
+      List the tables of the "cdm" schema.
+
+  goli team-features schema cdm.CDM_ENTITIES -d "my_database"
+      Show the columns, indexes, constraints and foreign keys of the CDM_ENTITIES table.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			table := ""
+			if len(args) > 0 {
+				table = args[0]
+			}
+			return SchemaFun(cf, *instances, dbName, schema, table)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbName, "db", "d", "", "the DB to browse")
+	cmd.Flags().StringVarP(&schema, "schema", "s", "", "list only the tables of the schema")
+
+	cmd.SetHelpTemplate(cmd.Long)
+
+	return cmd
+}
+
+func SchemaFun(cf *client.Client, instances *map[string][]*entities.Instance, dbName, schema, table string) error {
+	pgInstanceRaw, err := teamFunctionsUtils.GetPostgresInstance(dbName, instances)
+	if err != nil {
+		return err
+	}
+
+	if table == "" {
+		results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.TablesQuery(schema))
+		if err != nil {
+			return err
+		}
+		if len(results[0].Rows) == 0 {
+			outputUtils.PrintWarningMessage("There are no tables")
+			return nil
+		}
+		teamFunctionsUtils.PrintCompactTable(results[0])
+		return nil
+	}
+
+	results, err := teamFunctionsUtils.RunQueriesOnInstance(cf, pgInstanceRaw, db.DefaultStatementTimeout, teamFunctionsUtils.TableDetailsQueries(table)...)
+	if err != nil {
+		return err
+	}
+	return teamFunctionsUtils.PrintTableDetails(table, results)
+}
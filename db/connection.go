@@ -7,20 +7,20 @@ import (
 	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/olekukonko/tablewriter"
 	"golang.org/x/crypto/ssh"
 	. "goli-cli/types"
-	"goli-cli/utils"
-	"goli-cli/utils/applicationsUtils"
 	"goli-cli/utils/outputUtils"
 	"io"
 	"net"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -32,52 +32,51 @@ const (
 	POSTGRES = "postgres"
 )
 
-func OpenPostgresConnection(cf *client.Client, connectionInfo *ConnectionInfo, appGUID, appName string) error {
+// DefaultStatementTimeout is the time a query may run before the database cancels it
+const DefaultStatementTimeout = 60 * time.Second
 
-	stopChan, err := OpenConnectionToService(cf, connectionInfo, appGUID, POSTGRES, appName)
+// the time for connecting to the database through the tunnel, on top of the statement timeout
+const connectTimeout = 30 * time.Second
+
+// the local ports the tunnels are opened on by default
+const (
+	defaultPostgresPort = "5432"
+	defaultRedisPort    = "6380"
+)
+
+func OpenPostgresConnection(cf *client.Client, connectionInfo *ConnectionInfo, appGUID, appName string, localPort int, console bool) error {
+
+	stopChan, localCred, err := OpenConnectionToService(cf, connectionInfo, appGUID, POSTGRES, appName, localPort)
 	if err != nil {
 		return err
 	}
 
-	var localCred = *connectionInfo
-
-	localCred.Hostname = "127.0.0.1"
-	localCred.Port = "5432"
-
-	outputUtils.PrintInterface(localCred)
+	if console {
+		// the console cancels the running query on Ctrl+C, which must not close the tunnel under it
+		signal.Stop(stopChan)
+		err = OpenPostgresConsole(localCred)
+		closeTunnel(stopChan)
+		return err
+	}
 
-	err = OpenTablePlusClient(POSTGRES, connectionInfo)
+	outputUtils.PrintInterface(*localCred)
 
-	if err != nil && runtime.GOOS == "darwin" {
-		outputUtils.PrintErrorMessage("Error opening the connection:", err.Error())
-	} else {
-		// Wait for the user to close the connection
-		utils.StringPrompt("press enter to close the connection...")
-	}
-	stopChan <- os.Interrupt
+	err = OpenDbClient(POSTGRES, localCred)
+	closeTunnel(stopChan)
 	return err
 }
 
-func OpenRedisConnection(cf *client.Client, connectionInfo *ConnectionInfo, appGUID, appName string, isMasterNode bool) error {
-	stopChan, err := OpenConnectionToService(cf, connectionInfo, appGUID, REDIS, appName)
+func OpenRedisConnection(cf *client.Client, connectionInfo *ConnectionInfo, appGUID, appName string, isMasterNode bool, localPort int, console bool) error {
+	stopChan, localCred, err := OpenConnectionToService(cf, connectionInfo, appGUID, REDIS, appName, localPort)
 	if err != nil {
 		return err
 	}
-	var localCred = *connectionInfo
-
-	localCred.Hostname = "127.0.0.1"
-	localCred.Port = "6380"
 
 	// Test connection
 	if !isMasterNode {
-		rdb := redis.NewClient(&redis.Options{
-			Addr:     localCred.Hostname + ":" + localCred.Port,
-			Password: localCred.Password,
-			TLSConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		})
+		rdb := newRedisClient(localCred, 0)
 		pong, err := rdb.Do("ROLE").Result()
+		rdb.Close()
 		if err != nil {
 			outputUtils.PrintErrorMessage("Error connecting to Redis:", err.Error())
 			return err
@@ -90,72 +89,88 @@ func OpenRedisConnection(cf *client.Client, connectionInfo *ConnectionInfo, appG
 			stopChan <- os.Interrupt
 			<-stopChan
 			connectionInfo.Hostname = pong.([]any)[1].(string)
-			return OpenRedisConnection(cf, connectionInfo, appGUID, appName, true)
+			return OpenRedisConnection(cf, connectionInfo, appGUID, appName, true, localPort, console)
 		}
 	}
-	outputUtils.PrintInterface(localCred)
-
-	err = OpenTablePlusClient(REDIS, connectionInfo)
 
-	if err != nil && runtime.GOOS == "darwin" {
-		outputUtils.PrintErrorMessage("Error opening the connection:", err.Error())
-	} else {
-		// Wait for the user to close the connection
-		utils.StringPrompt("press enter to close the connection...")
+	if console {
+		// Ctrl+C while a command runs must not close the tunnel under the console
+		signal.Stop(stopChan)
+		err = OpenRedisConsole(localCred)
+		closeTunnel(stopChan)
+		return err
 	}
-	stopChan <- os.Interrupt
+
+	outputUtils.PrintInterface(*localCred)
+
+	err = OpenDbClient(REDIS, localCred)
+	closeTunnel(stopChan)
 	return err
 }
 
-func OpenConnectionToService(cf *client.Client, serviceCredentials *ConnectionInfo, appGUID, serviceName, appName string) (chan os.Signal, error) {
-	domain := utils.ExtractDomain(cf.Config.ApiURL(""))
-	var localPort string
-
-	switch serviceName {
-	case POSTGRES:
-		localPort = "5432"
-	case REDIS:
-		localPort = "6380"
+// closeTunnel closes the tunnel of OpenConnectionToService, unless it was already closed by a signal,
+// in which case the channel holds the nil the tunnel goroutine sent back
+func closeTunnel(stopChan chan os.Signal) {
+	select {
+	case stopChan <- os.Interrupt:
+	default:
 	}
+}
 
-	if !isPortFree(localPort) {
-		return nil, errors.New(fmt.Sprintf("Port %s is occupied!", localPort))
+// newRedisClient creates a client of the local credentials, with the default pool size if poolSize is 0
+func newRedisClient(cred *ConnectionInfo, poolSize int) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cred.Hostname + ":" + cred.Port,
+		Password: cred.Password,
+		PoolSize: poolSize,
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	})
+}
+
+// OpenConnectionToService opens a tunnel to the service and returns the credentials for connecting to it locally,
+// if localPort is 0 the default port of the service is used, or a free port when the default one is occupied
+func OpenConnectionToService(cf *client.Client, serviceCredentials *ConnectionInfo, appGUID, serviceName, appName string, localPort int) (chan os.Signal, *ConnectionInfo, error) {
+	port, err := resolveLocalPort(serviceName, localPort)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// stopChan is used for closing signal
-	stopChan, err := createConnection(cf, serviceCredentials, appGUID, serviceName, domain, appName)
-	return stopChan, err
+	stopChan, err := createConnection(cf, serviceCredentials, appGUID, appName, port)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localCred := *serviceCredentials
+	localCred.Hostname = "127.0.0.1"
+	localCred.Port = port
+	return stopChan, &localCred, nil
 }
 
-func OpenTablePlusClient(dbType string, serviceCredentials *ConnectionInfo) error {
-	var command string
-	switch dbType {
-	case POSTGRES:
-		command = fmt.Sprintf("postgresql://%s:%s@127.0.0.1/%s?statusColor=686B6F&env=local&name=temp&tLSMode=0&usePrivateKey=false&safeModeLevel=0&advancedSafeModeLevel=0&driverVersion=0",
-			serviceCredentials.Username, serviceCredentials.Password, serviceCredentials.Dbname)
-	case REDIS:
-		command = fmt.Sprintf("redis://:%s@127.0.0.1:6380?statusColor=686B6F&env=local&name=local&tLSMode=1&usePrivateKey=false&safeModeLevel=0&advancedSafeModeLevel=0&driverVersion=0&lazyload=true",
-			serviceCredentials.Password)
-	}
-
-	var cmd string
-	var args []string
-	switch runtime.GOOS {
-	case "windows":
-		cmd = "cmd"
-		args = []string{"/c", "start", command}
-		//fmt.Println("Unfortunately, opening TablePlus is not supported on Windows")
-		//fmt.Println("Please copy the following connection string and paste it in the browser")
-		//outputUtils.PrintSuccessMessage(command)
-	case "darwin":
-		cmd = "open"
-		args = []string{command}
-	}
-	err := exec.Command(cmd, args...).Run()
-	if err != nil && runtime.GOOS == "windows" {
-		err = nil
+func resolveLocalPort(serviceName string, localPort int) (string, error) {
+	if localPort != 0 {
+		port := strconv.Itoa(localPort)
+		if !isPortFree(port) {
+			return "", errors.New(fmt.Sprintf("Port %s is occupied!", port))
+		}
+		return port, nil
 	}
-	return err
+
+	port := defaultPostgresPort
+	if serviceName == REDIS {
+		port = defaultRedisPort
+	}
+	if isPortFree(port) {
+		return port, nil
+	}
+	freePort, err := findFreePort()
+	if err != nil {
+		return "", err
+	}
+	outputUtils.PrintWarningMessage(fmt.Sprintf("Port %s is occupied, using port %s instead", port, freePort))
+	return freePort, nil
 }
 
 func handleForwarding(client *ssh.Client, remoteHost string, remotePort string, listener net.Listener, openNewConnection chan bool) {
@@ -181,66 +196,11 @@ func handleForwarding(client *ssh.Client, remoteHost string, remotePort string,
 	io.Copy(conn, remoteConn)
 }
 
-func createConnection(cf *client.Client, serviceCredentials *ConnectionInfo, appGUID, serviceName, domain, appName string) (chan os.Signal, error) {
-	port := "2222"
-	var localPort string
-
-	switch serviceName {
-	case POSTGRES:
-		localPort = "5432"
-	case REDIS:
-		localPort = "6380"
-	}
-
-	server := fmt.Sprintf("ssh.cf.%s", domain)
-	process, err := cf.Processes.First(context.Background(), &client.ProcessListOptions{
-		AppGUIDs: client.Filter{Values: []string{appGUID}},
-	})
+func createConnection(cf *client.Client, serviceCredentials *ConnectionInfo, appGUID, appName, localPort string) (chan os.Signal, error) {
+	sshClient, err := OpenSshClient(cf, appGUID, appName, 0)
 	if err != nil {
 		return nil, err
 	}
-	user := fmt.Sprintf("cf:%s/0", process.GUID)
-	password, err := cf.SSHCode(context.Background())
-	if err != nil {
-		return nil, err
-	}
-	config := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	sshClient, err := ssh.Dial("tcp", fmt.Sprintf("%s:%s", server, port), config)
-	if err != nil {
-		if strings.Contains(err.Error(), "unable to authenticate") {
-			res, internalErr := cf.Processes.GetStatsForApp(context.Background(), appGUID, "web")
-			if internalErr != nil {
-				return nil, internalErr
-			}
-			for _, instance := range res.Stats {
-				if instance.State != "RUNNING" {
-					return nil, errors.New("app is not running... please start the app and try again")
-				}
-			}
-			outputUtils.PrintWarningMessage("you are not authorized to perform the requested action (maybe SSH access is off?)")
-			ans := utils.QuestionPrompt("Do you want to enable ssh?")
-			if !ans {
-				return nil, err
-			}
-			err = applicationsUtils.EnableAppSsh(cf, appGUID)
-			if err != nil {
-				return nil, err
-			}
-			err = applicationsUtils.RestartAppRolling(cf, appGUID, appName)
-			if err != nil {
-				return nil, err
-			}
-			return createConnection(cf, serviceCredentials, appGUID, serviceName, domain, appName)
-		} else {
-			return nil, err
-		}
-	}
 
 	// Remote host and port to forward to
 	remoteHost := serviceCredentials.Hostname
@@ -266,14 +226,15 @@ func createConnection(cf *client.Client, serviceCredentials *ConnectionInfo, app
 	}()
 
 	stopChan := make(chan os.Signal, 1)
+	// registered before returning, so the caller can stop the signals with signal.Stop
+	signal.Notify(stopChan,
+		os.Interrupt,
+		syscall.SIGINT,
+		syscall.SIGKILL,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
 
 	go func() {
-		signal.Notify(stopChan,
-			os.Interrupt,
-			syscall.SIGINT,
-			syscall.SIGKILL,
-			syscall.SIGTERM,
-			syscall.SIGQUIT)
 		<-stopChan
 		stop = true
 		homeDir, _ := os.UserHomeDir()
@@ -290,89 +251,81 @@ func createConnection(cf *client.Client, serviceCredentials *ConnectionInfo, app
 	return stopChan, nil
 }
 
+// RunQuery runs the query on the database of the local credentials returned by OpenConnectionToService
 func RunQuery(cred *ConnectionInfo, query string, forPrint bool) ([][]string, error) {
-	databaseURL := fmt.Sprintf("postgresql://%s:%s@127.0.0.1:5432/%s",
-		cred.Username, cred.Password, cred.Dbname)
-
-	// Create a connection pool
-	config, err := pgxpool.ParseConfig(databaseURL)
+	result, err := RunTypedQuery(cred, query, DefaultStatementTimeout, 0)
 	if err != nil {
 		return nil, err
 	}
+	return result.StringRows(forPrint), nil
+}
 
-	// Set connection pool settings (optional)
-	config.MaxConns = 10 // max 10 connections
-	config.MaxConnLifetime = 30 * time.Minute
-
-	// Connect to the database
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-	dbpool, err := pgxpool.NewWithConfig(ctx, config)
+// RunTypedQuery runs the query like RunQuery with the values of its positional parameters, and keeps the values of the column types.
+// The query runs in a read-only transaction with the statement timeout, so the database rejects any statement that writes,
+// and at most limit rows are read if the limit is set
+func RunTypedQuery(cred *ConnectionInfo, query string, statementTimeout time.Duration, limit int, args ...any) (*QueryResult, error) {
+	writer := &resultWriter{result: &QueryResult{}}
+	_, err := StreamQuery(cred, query, statementTimeout, StreamOptions{Limit: limit}, writer, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer dbpool.Close()
+	return writer.result, nil
+}
 
-	fmt.Println("Connected to the database successfully!")
+// the SQLSTATE codes of writing in a read-only transaction and of canceled statements
+const (
+	readOnlySQLTransactionCode = "25006"
+	queryCanceledCode          = "57014"
+)
 
-	// Example Query: Fetch data from a table
-	rows, err := dbpool.Query(ctx, query)
-	defer rows.Close()
+// explainQueryError explains the errors of the read-only transaction and of the statement timeout
+func explainQueryError(err error, statementTimeout time.Duration) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case readOnlySQLTransactionCode:
+		return fmt.Errorf("only read-only queries can be run: %s", pgErr.Message)
+	case queryCanceledCode:
+		return fmt.Errorf("the query was canceled after the statement timeout of %s, use --timeout to allow a longer time", statementTimeout)
+	}
+	return err
+}
 
+// NewPostgresPool creates a connection pool to the database of the local credentials
+func NewPostgresPool(ctx context.Context, cred *ConnectionInfo) (*pgxpool.Pool, error) {
+	config, err := postgresPoolConfig(cred)
 	if err != nil {
 		return nil, err
 	}
+	return pgxpool.NewWithConfig(ctx, config)
+}
 
-	columnDescriptions := rows.FieldDescriptions()
-	numColumns := len(columnDescriptions)
+func postgresPoolConfig(cred *ConnectionInfo) (*pgxpool.Config, error) {
+	databaseURL := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s",
+		cred.Username, cred.Password, cred.Hostname, cred.Port, cred.Dbname)
 
-	// Slice to store all rows
-	var results [][]string
-	if forPrint {
-		columnNames := make([]string, numColumns)
-		for i, column := range columnDescriptions {
-			columnNames[i] = column.Name
-		}
-		results = append(results, columnNames)
+	// Create the configuration of the connection pool
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Iterate through each row
-	for rows.Next() {
-		// Create a slice to hold the values of the current row
-		rowData := make([]interface{}, numColumns)
-		rowPointers := make([]interface{}, numColumns)
-
-		// Initialize the row pointers to point to each element in the rowData slice
-		for i := range rowData {
-			rowPointers[i] = &rowData[i]
-		}
-
-		// Scan the row into the rowPointers (which will populate rowData)
-		err := rows.Scan(rowPointers...)
-		if err != nil {
-			return nil, fmt.Errorf("unable to scan the row: %v", err)
-		}
-		stringRow := make([]string, numColumns)
-		for i, col := range rowData {
-			if col != nil {
-				stringRow[i] = fmt.Sprintf("%v", col)
-				if strings.HasPrefix(stringRow[i], "[") {
-					stringRow[i] = convertUUID(col)
-				}
-			} else {
-				stringRow[i] = "NULL" // Handle null values
-			}
-		}
+	// Set connection pool settings (optional)
+	config.MaxConns = 10 // max 10 connections
+	config.MaxConnLifetime = 30 * time.Minute
 
-		// Append the scanned row (rowData) to the results
-		results = append(results, stringRow)
-	}
+	return config, nil
+}
 
-	// Check for any errors during the iteration
-	if rows.Err() != nil {
-		return nil, rows.Err()
+// ScanRows reads the rows as strings, with the column names as the first row if withHeader is set
+func ScanRows(rows pgx.Rows, withHeader bool) ([][]string, error) {
+	result, err := ScanResult(rows)
+	if err != nil {
+		return nil, err
 	}
-	return results, nil
+	return result.StringRows(withHeader), nil
 }
 
 func convertUUID(v interface{}) string {
@@ -425,6 +378,15 @@ func isPortFree(port string) bool {
 	return true
 }
 
+func findFreePort() (string, error) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+	return strconv.Itoa(ln.Addr().(*net.TCPAddr).Port), nil
+}
+
 func GetPostgresConnectionInfo(cred map[string]interface{}) (*ConnectionInfo, error) {
 	info := &ConnectionInfo{
 		Hostname: cred["hostname"].(string),
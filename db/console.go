@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "goli-cli/types"
+	"goli-cli/utils/outputUtils"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// the console history is kept in the goli folder, so it is shared between the sessions
+const consoleHistoryFile = "sqlHistory"
+
+const consoleHelp = `  \dt             list the tables
+  \d TABLE        describe the columns of a table, TABLE may be qualified with a schema
+  \x              toggle the expanded display
+  \timing         toggle printing the execution time of the queries
+  \?              show this help
+  \q              quit the console
+Queries can span several lines and are executed once a line ends with ';'.`
+
+const listTablesQuery = `select table_schema as "Schema", table_name as "Name", table_type as "Type"
+from information_schema.tables
+where table_schema not in ('pg_catalog', 'information_schema')
+order by table_schema, table_name`
+
+const describeTableQuery = `select column_name as "Column", data_type as "Type", is_nullable as "Nullable", coalesce(column_default, '') as "Default"
+from information_schema.columns
+where table_name = $1 and ($2 = '' or table_schema = $2)
+order by table_schema, ordinal_position`
+
+type postgresConsole struct {
+	pool     *pgxpool.Pool
+	expanded bool
+	timing   bool
+	// receives Ctrl+C while a query runs, when the terminal is not in the raw mode of readline
+	interrupted chan os.Signal
+}
+
+// OpenPostgresConsole runs an interactive SQL console on the database of the local credentials until the user quits it
+func OpenPostgresConsole(cred *ConnectionInfo) error {
+	config, err := postgresPoolConfig(cred)
+	if err != nil {
+		return err
+	}
+	// a canceled query is canceled on the server too, instead of only closing its connection
+	config.ConnConfig.BuildContextWatcherHandler = func(pgConn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{Conn: pgConn, DeadlineDelay: 5 * time.Second}
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	prompt := cred.Dbname + "=> "
+	continuationPrompt := cred.Dbname + "-> "
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:                 prompt,
+		HistoryFile:            consoleHistoryFile,
+		DisableAutoSaveHistory: true,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              `\q`,
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	console := &postgresConsole{pool: pool, interrupted: make(chan os.Signal, 1)}
+	signal.Notify(console.interrupted, os.Interrupt)
+	defer signal.Stop(console.interrupted)
+	fmt.Println("Connected to", color.HiCyanString(cred.Dbname)+`, type \? for help and \q to quit.`)
+
+	var buffer []string
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			// Ctrl+C drops the query that is being typed
+			buffer = nil
+			rl.SetPrompt(prompt)
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if len(buffer) == 0 {
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, `\`) {
+				rl.SaveHistory(trimmed)
+				if trimmed == `\q` {
+					return nil
+				}
+				console.runMetaCommand(trimmed)
+				continue
+			}
+		}
+
+		buffer = append(buffer, line)
+		if !strings.HasSuffix(trimmed, ";") {
+			rl.SetPrompt(continuationPrompt)
+			continue
+		}
+		query := strings.Join(buffer, "\n")
+		buffer = nil
+		rl.SetPrompt(prompt)
+		// multi-line queries are kept in the history as a single line, so they can be recalled at once
+		rl.SaveHistory(strings.Join(strings.Fields(query), " "))
+		console.execute(query)
+	}
+}
+
+func (console *postgresConsole) runMetaCommand(command string) {
+	fields := strings.Fields(command)
+	switch fields[0] {
+	case `\dt`:
+		console.execute(listTablesQuery)
+	case `\d`:
+		if len(fields) != 2 {
+			outputUtils.PrintErrorMessage(`usage: \d TABLE`)
+			return
+		}
+		schema, table := splitTableName(fields[1])
+		console.execute(describeTableQuery, table, schema)
+	case `\x`:
+		console.expanded = !console.expanded
+		fmt.Println("Expanded display is", onOff(console.expanded)+".")
+	case `\timing`:
+		console.timing = !console.timing
+		fmt.Println("Timing is", onOff(console.timing)+".")
+	case `\?`:
+		fmt.Println(consoleHelp)
+	default:
+		outputUtils.PrintErrorMessage(fmt.Sprintf(`invalid command %s, type \? for help`, fields[0]))
+	}
+}
+
+func (console *postgresConsole) execute(query string, args ...any) {
+	ctx, cancel := console.interruptibleContext()
+	defer cancel()
+	start := time.Now()
+	rows, err := console.pool.Query(ctx, query, args...)
+	if err != nil {
+		outputUtils.PrintErrorMessage(err.Error())
+		return
+	}
+	results, err := ScanRows(rows, true)
+	rows.Close()
+	if err != nil {
+		outputUtils.PrintErrorMessage(err.Error())
+		return
+	}
+	duration := time.Since(start)
+
+	if len(rows.FieldDescriptions()) == 0 {
+		// statements without a result, e.g. UPDATE, print the command tag like psql
+		fmt.Println(rows.CommandTag().String())
+	} else {
+		if console.expanded {
+			printExpanded(results)
+		} else {
+			PrintQueryResult(results)
+		}
+		if len(results) == 2 {
+			fmt.Println("(1 row)")
+		} else {
+			fmt.Printf("(%d rows)\n", len(results)-1)
+		}
+	}
+	if console.timing {
+		fmt.Printf("Time: %.3f ms\n", float64(duration.Microseconds())/1000)
+	}
+}
+
+// interruptibleContext returns a context that is canceled when the user clicks on Ctrl+C, so the running query is canceled
+func (console *postgresConsole) interruptibleContext() (context.Context, context.CancelFunc) {
+	// a Ctrl+C while the previous result was printed is dropped
+	select {
+	case <-console.interrupted:
+	default:
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-console.interrupted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// printExpanded prints every record as a list of column and value pairs, for results with many columns
+func printExpanded(results [][]string) {
+	header := results[0]
+	width := 0
+	for _, column := range header {
+		width = max(width, len(column))
+	}
+	for index, row := range results[1:] {
+		fmt.Println(color.HiBlackString("-[ RECORD %d ]-", index+1))
+		for i, value := range row {
+			fmt.Printf("%-*s | %s\n", width, header[i], value)
+		}
+	}
+}
+
+// splitTableName splits a table name that may be qualified with a schema, the quotes of quoted identifiers are dropped
+func splitTableName(name string) (string, string) {
+	name = strings.ReplaceAll(name, `"`, "")
+	if schema, table, found := strings.Cut(name, "."); found {
+		return schema, table
+	}
+	return "", name
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	. "goli-cli/types"
+	"io"
+	"strings"
+	"time"
+)
+
+// QuoteTableName quotes the table name, optionally qualified with the schema, so its case is kept
+func QuoteTableName(table string) string {
+	if schema, name, found := strings.Cut(table, "."); found {
+		return pgx.Identifier{schema, name}.Sanitize()
+	}
+	return pgx.Identifier{table}.Sanitize()
+}
+
+// CopyStatement returns the COPY statement of the table, TO STDOUT with the rows that match the where condition,
+// or FROM STDIN into the columns
+func CopyStatement(table, where string, columns []string, from bool) string {
+	if from {
+		return fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv)", QuoteTableName(table), strings.Join(quoteIdentifiers(columns), ", "))
+	}
+	if where == "" {
+		return fmt.Sprintf("COPY %s TO STDOUT WITH (FORMAT csv, HEADER)", QuoteTableName(table))
+	}
+	return fmt.Sprintf("COPY (SELECT * FROM %s WHERE (%s)) TO STDOUT WITH (FORMAT csv, HEADER)", QuoteTableName(table), where)
+}
+
+// CopyTo writes the rows of the table that match the where condition as CSV with a header to the writer,
+// using COPY TO STDOUT in a read-only transaction, and returns the number of rows
+func CopyTo(cred *ConnectionInfo, table, where string, statementTimeout time.Duration, writer io.Writer) (int64, error) {
+	ctx := context.Background()
+	dbpool, tx, err := beginReadOnly(ctx, cred, statementTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer dbpool.Close()
+	// nothing is ever committed, the transaction is read-only
+	defer tx.Rollback(ctx)
+
+	if where != "" {
+		if err = validateCondition(ctx, tx, table, where); err != nil {
+			return 0, explainQueryError(err, statementTimeout)
+		}
+	}
+	tag, err := tx.Conn().PgConn().CopyTo(ctx, writer, CopyStatement(table, where, nil, false))
+	if err != nil {
+		return 0, explainQueryError(err, statementTimeout)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CopyFrom copies the CSV rows of the reader, without a header, into the columns of the table using COPY FROM STDIN,
+// and commits the transaction only if confirm returns true for the result
+func CopyFrom(cred *ConnectionInfo, table string, columns []string, reader io.Reader, statementTimeout time.Duration, confirm func(result *StatementResult) bool) (*StatementResult, bool, error) {
+	// the transaction may wait for the confirmation of the user, so only the copy is limited by the timeout
+	ctx := context.Background()
+	dbpool, tx, err := beginTx(ctx, cred, statementTimeout, pgx.ReadWrite)
+	if err != nil {
+		return nil, false, err
+	}
+	defer dbpool.Close()
+	// does nothing once the transaction is committed
+	defer tx.Rollback(ctx)
+
+	result := &StatementResult{Statement: CopyStatement(table, "", columns, true)}
+	tag, err := tx.Conn().PgConn().CopyFrom(ctx, reader, result.Statement)
+	if err != nil {
+		result.Error = err.Error()
+		return result, false, fmt.Errorf("the copy failed, the transaction was rolled back: %v", explainQueryError(err, statementTimeout))
+	}
+	result.CommandTag = tag.String()
+	result.RowsAffected = tag.RowsAffected()
+
+	if !confirm(result) {
+		return result, false, tx.Rollback(ctx)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return result, false, err
+	}
+	return result, true, nil
+}
+
+// validateCondition checks that the where condition is a single expression before it is put in the COPY statement,
+// which is sent over the simple protocol that runs every statement of the string, so a condition like
+// "true) TO STDOUT; COMMIT; DELETE FROM t; COPY (SELECT 1" would end the read-only transaction.
+// Query uses the extended protocol, where PostgreSQL rejects several statements
+func validateCondition(ctx context.Context, tx pgx.Tx, table, where string) error {
+	rows, err := tx.Query(ctx, fmt.Sprintf("SELECT * FROM %s WHERE (%s) LIMIT 0", QuoteTableName(table), where))
+	if err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("invalid condition: %w", err)
+	}
+	return nil
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return quoted
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// the DB clients that can be set in the dbClients section of config.json, any other value is used as a custom command template
+const (
+	TablePlus = "tableplus"
+	Psql      = "psql"
+	Pgcli     = "pgcli"
+	DBeaver   = "dbeaver"
+	RedisCli  = "redis-cli"
+)
+
+type dbClient struct {
+	dbType  string
+	command string
+	// terminal clients run in the foreground and the tunnel is closed when they exit,
+	// other clients are started in the background and the tunnel is closed when the user presses enter
+	terminal bool
+}
+
+var dbClients = map[string]dbClient{
+	Psql:     {dbType: POSTGRES, command: "psql -h {host} -p {port} -U {user} {db}", terminal: true},
+	Pgcli:    {dbType: POSTGRES, command: "pgcli -h {host} -p {port} -U {user} {db}", terminal: true},
+	DBeaver:  {dbType: POSTGRES, command: "dbeaver -con driver=postgresql|host={host}|port={port}|database={db}|user={user}|password={password}|connect=true"},
+	RedisCli: {dbType: REDIS, command: "redis-cli -h {host} -p {port} --tls --insecure", terminal: true},
+}
+
+// OpenDbClient opens the client that is configured for the DB type in config.json and returns when the user is done with it,
+// TablePlus is used if no client is configured
+func OpenDbClient(dbType string, localCred *ConnectionInfo) error {
+	clientName := configuredDbClient(dbType)
+	if clientName == "" || clientName == TablePlus {
+		err := OpenTablePlusClient(dbType, localCred)
+		if err != nil && runtime.GOOS == "darwin" {
+			outputUtils.PrintErrorMessage("Error opening the connection:", err.Error())
+			return err
+		} else if err != nil {
+			// the tunnel can still be used with the printed credentials
+			outputUtils.PrintWarningMessage("Failed to open TablePlus, connect with the credentials above:", err.Error())
+		}
+		// Wait for the user to close the connection
+		utils.StringPrompt("press enter to close the connection...")
+		return nil
+	}
+
+	client, ok := dbClients[clientName]
+	if !ok {
+		client = dbClient{dbType: dbType, command: clientName, terminal: true}
+	} else if client.dbType != dbType {
+		return fmt.Errorf("the client %s does not support %s, change the dbClients section in config.json", clientName, dbType)
+	}
+
+	args := strings.Fields(client.command)
+	for i := range args {
+		args[i] = fillClientTemplate(args[i], localCred)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	// the password is passed in the environment where the clients support it, so it does not show up in the process list
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+localCred.Password, "REDISCLI_AUTH="+localCred.Password)
+
+	if !client.terminal {
+		err := cmd.Start()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", args[0], err)
+		}
+		utils.StringPrompt("press enter to close the connection...")
+		return nil
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if _, exited := err.(*exec.ExitError); exited {
+		// the exit status of the client is not an error of the connection
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", args[0], err)
+	}
+	return nil
+}
+
+func configuredDbClient(dbType string) string {
+	var goliConfig LocalConfig
+	configRaw, err := os.ReadFile("config.json")
+	if err != nil {
+		return ""
+	}
+	err = json.Unmarshal(configRaw, &goliConfig)
+	if err != nil {
+		outputUtils.PrintWarningMessage("Failed to read the DB clients from config.json:", err.Error())
+		return ""
+	}
+	if dbType == REDIS {
+		return goliConfig.DbClients.Redis
+	}
+	return goliConfig.DbClients.Postgres
+}
+
+func fillClientTemplate(arg string, cred *ConnectionInfo) string {
+	return strings.NewReplacer(
+		"{host}", cred.Hostname,
+		"{port}", cred.Port,
+		"{user}", cred.Username,
+		"{password}", cred.Password,
+		"{db}", cred.Dbname,
+	).Replace(arg)
+}
+
+func OpenTablePlusClient(dbType string, serviceCredentials *ConnectionInfo) error {
+	var command string
+	switch dbType {
+	case POSTGRES:
+		command = fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?statusColor=686B6F&env=local&name=temp&tLSMode=0&usePrivateKey=false&safeModeLevel=0&advancedSafeModeLevel=0&driverVersion=0",
+			serviceCredentials.Username, serviceCredentials.Password, serviceCredentials.Hostname, serviceCredentials.Port, serviceCredentials.Dbname)
+	case REDIS:
+		command = fmt.Sprintf("redis://:%s@%s:%s?statusColor=686B6F&env=local&name=local&tLSMode=1&usePrivateKey=false&safeModeLevel=0&advancedSafeModeLevel=0&driverVersion=0&lazyload=true",
+			serviceCredentials.Password, serviceCredentials.Hostname, serviceCredentials.Port)
+	}
+
+	var cmd string
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		cmd = "cmd"
+		args = []string{"/c", "start", command}
+	case "darwin":
+		cmd = "open"
+		args = []string{command}
+	default:
+		cmd = "xdg-open"
+		args = []string{command}
+	}
+	err := exec.Command(cmd, args...).Run()
+	if err != nil && runtime.GOOS == "windows" {
+		err = nil
+	}
+	return err
+}
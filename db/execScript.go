@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	. "goli-cli/types"
+	"time"
+)
+
+type StatementResult struct {
+	Statement    string `json:"statement"`
+	CommandTag   string `json:"commandTag,omitempty"`
+	RowsAffected int64  `json:"rowsAffected"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ExecScript runs the statements in a single transaction and commits it only if confirm returns true for their results,
+// if a statement fails the transaction is rolled back and the results include the failed statement
+func ExecScript(cred *ConnectionInfo, statements []string, statementTimeout time.Duration, confirm func(results []*StatementResult) bool) ([]*StatementResult, bool, error) {
+	// the transaction may wait for the confirmation of the user, so only the statements are limited by the timeout
+	ctx := context.Background()
+	dbpool, tx, err := beginTx(ctx, cred, statementTimeout, pgx.ReadWrite)
+	if err != nil {
+		return nil, false, err
+	}
+	defer dbpool.Close()
+	// does nothing once the transaction is committed
+	defer tx.Rollback(ctx)
+
+	var results []*StatementResult
+	for index, statement := range statements {
+		result := &StatementResult{Statement: statement}
+		results = append(results, result)
+		tag, err := tx.Exec(ctx, statement)
+		if err != nil {
+			result.Error = err.Error()
+			return results, false, fmt.Errorf("statement %d failed, the transaction was rolled back: %v", index+1, explainQueryError(err, statementTimeout))
+		}
+		result.CommandTag = tag.String()
+		result.RowsAffected = tag.RowsAffected()
+	}
+
+	if !confirm(results) {
+		return results, false, tx.Rollback(ctx)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return results, false, err
+	}
+	return results, true, nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	. "goli-cli/types"
+	"strings"
+	"time"
+)
+
+// ExplainQuery returns the plan of the query as the JSON of EXPLAIN (FORMAT JSON), in a read-only transaction.
+// With analyze the query is executed to measure the actual rows and times, a statement that writes is refused by PostgreSQL
+func ExplainQuery(cred *ConnectionInfo, query string, analyze bool, statementTimeout time.Duration, args ...any) (string, error) {
+	plan, err := explainInTx(cred, query, analyze, statementTimeout, args)
+	return plan, explainQueryError(err, statementTimeout)
+}
+
+func explainInTx(cred *ConnectionInfo, query string, analyze bool, statementTimeout time.Duration, args []any) (string, error) {
+	ctx := context.Background()
+	dbpool, tx, err := beginReadOnly(ctx, cred, statementTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer dbpool.Close()
+	// nothing is ever committed, the transaction is read-only
+	defer tx.Rollback(ctx)
+
+	options := "FORMAT JSON"
+	if analyze {
+		options += ", ANALYZE, BUFFERS"
+	}
+	var plan string
+	err = tx.QueryRow(ctx, "EXPLAIN ("+options+") "+strings.TrimRight(strings.TrimSpace(query), ";"), args...).Scan(&plan)
+	return plan, err
+}
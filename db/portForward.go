@@ -0,0 +1,125 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	. "goli-cli/types"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Forwarder listens on a local port and forwards every accepted connection through the ssh client
+type Forwarder struct {
+	Mapping  *PortMapping
+	Active   int64
+	Total    int64
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// ParsePortMapping parses a 'LOCAL_PORT:REMOTE_HOST:REMOTE_PORT' mapping, 'LOCAL_PORT:REMOTE_PORT' forwards to the container itself
+func ParsePortMapping(value string) (*PortMapping, error) {
+	parts := strings.Split(value, ":")
+	var mapping *PortMapping
+	switch len(parts) {
+	case 2:
+		mapping = &PortMapping{LocalPort: parts[0], RemoteHost: "localhost", RemotePort: parts[1]}
+	case 3:
+		mapping = &PortMapping{LocalPort: parts[0], RemoteHost: parts[1], RemotePort: parts[2]}
+	default:
+		return nil, fmt.Errorf("invalid mapping '%s' - expected LOCAL_PORT:REMOTE_HOST:REMOTE_PORT", value)
+	}
+	for _, port := range []string{mapping.LocalPort, mapping.RemotePort} {
+		portAsInt, err := strconv.Atoi(port)
+		if err != nil || portAsInt < 1 || portAsInt > 65535 {
+			return nil, fmt.Errorf("invalid port '%s' in mapping '%s'", port, value)
+		}
+	}
+	if mapping.RemoteHost == "" {
+		return nil, fmt.Errorf("missing remote host in mapping '%s'", value)
+	}
+	return mapping, nil
+}
+
+// StartPortForwarding starts a forwarder for every mapping, if one of them fails all of the started ones are closed
+func StartPortForwarding(sshClient *ssh.Client, mappings []*PortMapping) ([]*Forwarder, error) {
+	var forwarders []*Forwarder
+	for _, mapping := range mappings {
+		if !isPortFree(mapping.LocalPort) {
+			closeListeners(forwarders)
+			return nil, errors.New(fmt.Sprintf("Port %s is occupied!", mapping.LocalPort))
+		}
+		listener, err := net.Listen("tcp", "127.0.0.1:"+mapping.LocalPort)
+		if err != nil {
+			closeListeners(forwarders)
+			return nil, err
+		}
+		forwarder := &Forwarder{Mapping: mapping, listener: listener}
+		// the accept loop is tracked too, so a connection it accepts is added before StopPortForwarding waits
+		forwarder.wg.Add(1)
+		go forwarder.acceptConnections(sshClient)
+		forwarders = append(forwarders, forwarder)
+	}
+	return forwarders, nil
+}
+
+// StopPortForwarding closes the listeners and the ssh client and waits for the open connections to be closed
+func StopPortForwarding(sshClient *ssh.Client, forwarders []*Forwarder) {
+	closeListeners(forwarders)
+	sshClient.Close()
+	for _, forwarder := range forwarders {
+		forwarder.wg.Wait()
+	}
+}
+
+func closeListeners(forwarders []*Forwarder) {
+	for _, forwarder := range forwarders {
+		forwarder.listener.Close()
+	}
+}
+
+func (f *Forwarder) acceptConnections(sshClient *ssh.Client) {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			// the listener is closed
+			return
+		}
+		f.wg.Add(1)
+		go f.forward(sshClient, conn)
+	}
+}
+
+func (f *Forwarder) forward(sshClient *ssh.Client, conn net.Conn) {
+	defer f.wg.Done()
+	defer conn.Close()
+
+	remoteConn, err := sshClient.Dial("tcp", net.JoinHostPort(f.Mapping.RemoteHost, f.Mapping.RemotePort))
+	if err != nil {
+		fmt.Printf("\nError dialing %s:%s: %s\n", f.Mapping.RemoteHost, f.Mapping.RemotePort, err)
+		return
+	}
+	defer remoteConn.Close()
+
+	atomic.AddInt64(&f.Active, 1)
+	atomic.AddInt64(&f.Total, 1)
+	defer atomic.AddInt64(&f.Active, -1)
+
+	done := make(chan bool, 2)
+	go func() {
+		io.Copy(remoteConn, conn)
+		done <- true
+	}()
+	go func() {
+		io.Copy(conn, remoteConn)
+		done <- true
+	}()
+	// once one side is closed, the other one is closed by the deferred calls
+	<-done
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math"
+	"strconv"
+	"time"
+)
+
+// QueryResult holds the rows of a query with the values of their column types,
+// NULL is nil and json/jsonb columns are decoded into maps and slices
+type QueryResult struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// ScanResult reads the rows and converts the values that have no natural representation, e.g. UUIDs and numerics
+func ScanResult(rows pgx.Rows) (*QueryResult, error) {
+	result := &QueryResult{Columns: fieldNames(rows)}
+	for rows.Next() {
+		values, _, err := scanValues(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, values)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return result, nil
+}
+
+// resultWriter collects the streamed rows in a QueryResult
+type resultWriter struct {
+	result *QueryResult
+}
+
+func (writer *resultWriter) WriteColumns(columns []string) error {
+	writer.result.Columns = columns
+	return nil
+}
+
+func (writer *resultWriter) WriteRow(row []any) error {
+	writer.result.Rows = append(writer.result.Rows, row)
+	return nil
+}
+
+func (writer *resultWriter) EndPage(bool) (bool, error) {
+	return true, nil
+}
+
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, []byte, time.Time, json.Number, map[string]any,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return normalizeFloat(float64(v), v)
+	case float64:
+		return normalizeFloat(v, v)
+	case [16]byte:
+		return convertUUID(v)
+	case []any:
+		for i := range v {
+			v[i] = normalizeValue(v[i])
+		}
+		return v
+	case pgtype.Numeric:
+		number, err := v.Value()
+		if err != nil || number == nil {
+			return nil
+		}
+		if parsed, err := strconv.ParseFloat(number.(string), 64); err == nil && !math.IsInf(parsed, 0) && !math.IsNaN(parsed) {
+			return json.Number(number.(string))
+		}
+		return number
+	case driver.Valuer:
+		// most of the other pgtype types, e.g. intervals, have a text representation
+		converted, err := v.Value()
+		if err == nil {
+			return normalizeValue(converted)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// NaN and infinity are kept as strings since JSON has no representation for them
+func normalizeFloat(number float64, value any) any {
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+	return value
+}
+
+// FormatValue returns the text representation of a value of the result, as shown in tables and CSV files
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return v
+	case []byte:
+		return fmt.Sprintf("\\x%x", v)
+	case map[string]any, []any:
+		valueJson, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(valueJson)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// StringRows returns the rows as text, with the column names as the first row if withHeader is set
+func (result *QueryResult) StringRows(withHeader bool) [][]string {
+	var rows [][]string
+	if withHeader {
+		rows = append(rows, result.Columns)
+	}
+	for _, row := range result.Rows {
+		stringRow := make([]string, len(row))
+		for i, value := range row {
+			stringRow[i] = FormatValue(value)
+		}
+		rows = append(rows, stringRow)
+	}
+	return rows
+}
@@ -0,0 +1,344 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "goli-cli/types"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// the number of rows fetched from the cursor at once when the rows are not paged
+const streamBatchSize = 1000
+
+// the name of the cursor the rows of a streamed query are fetched from
+const streamCursor = "goli_stream"
+
+// QueryKeywords are the first keywords of the statements that return rows and a cursor can be declared for,
+// other statements are rejected, since a read-only transaction still allows statements like DO
+var QueryKeywords = []string{"SELECT", "WITH", "VALUES", "TABLE"}
+
+// RowWriter receives the rows of a query while they are read from the database
+type RowWriter interface {
+	WriteColumns(columns []string) error
+	WriteRow(row []any) error
+	// EndPage is called after every page of rows and after the last row, it returns false to stop reading the rows
+	EndPage(last bool) (bool, error)
+}
+
+type StreamOptions struct {
+	// the maximum number of rows that are written, 0 writes all the rows
+	Limit int
+	// the number of rows after which EndPage is called, 0 calls it only after the last row
+	PageSize int
+}
+
+type QueryStats struct {
+	// the number of rows written
+	Rows int
+	// set if the query has more rows than were written, because of the limit or because the paging was stopped
+	Truncated bool
+	// the time spent reading the rows, without the time spent in EndPage, e.g. waiting for the next page
+	Elapsed time.Duration
+}
+
+// rowSource returns the rows of a query one by one
+type rowSource interface {
+	columns() []string
+	next() ([]any, bool, error)
+	close()
+}
+
+// StreamQuery runs the query in a read-only transaction with the statement timeout like RunTypedQuery,
+// and passes the rows to the writer while they are read, so the result does not have to fit in memory.
+// Only SELECT, WITH, VALUES and TABLE queries are accepted, they are read through a cursor, so the statement timeout applies to every page of rows
+func StreamQuery(cred *ConnectionInfo, query string, statementTimeout time.Duration, options StreamOptions, writer RowWriter, args ...any) (*QueryStats, error) {
+	// the rows may be paged by the user, so only the statements are limited by the timeout
+	ctx := context.Background()
+	dbpool, tx, err := beginReadOnly(ctx, cred, statementTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer dbpool.Close()
+	// nothing is ever committed, the transaction is read-only
+	defer tx.Rollback(ctx)
+
+	start := time.Now()
+	var paused time.Duration
+	source, err := openRowSource(ctx, tx, query, options, args)
+	if err != nil {
+		return nil, explainQueryError(err, statementTimeout)
+	}
+	defer source.close()
+
+	err = writer.WriteColumns(source.columns())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &QueryStats{}
+	row, ok, err := source.next()
+	for ok && err == nil {
+		err = writer.WriteRow(row)
+		if err != nil {
+			return nil, err
+		}
+		stats.Rows++
+
+		// the next row is read before ending the page, to know if the page is the last one
+		row, ok, err = source.next()
+		if err != nil {
+			break
+		}
+		limitReached := options.Limit > 0 && stats.Rows == options.Limit
+		last := !ok || limitReached
+		if last || (options.PageSize > 0 && stats.Rows%options.PageSize == 0) {
+			pageStart := time.Now()
+			more, err := writer.EndPage(last)
+			paused += time.Since(pageStart)
+			if err != nil {
+				return nil, err
+			}
+			if last || !more {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, explainQueryError(err, statementTimeout)
+	}
+	if stats.Rows == 0 {
+		_, err = writer.EndPage(true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the row read ahead was not written
+	stats.Truncated = ok
+	stats.Elapsed = time.Since(start) - paused
+	return stats, nil
+}
+
+// RunTypedQueries runs the queries one after the other in a single read-only transaction with the statement timeout,
+// so several queries need only one connection
+func RunTypedQueries(cred *ConnectionInfo, statementTimeout time.Duration, queries ...Query) ([]*QueryResult, error) {
+	ctx := context.Background()
+	dbpool, tx, err := beginReadOnly(ctx, cred, statementTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer dbpool.Close()
+	defer tx.Rollback(ctx)
+
+	var results []*QueryResult
+	for _, query := range queries {
+		result, err := runOptionalQuery(ctx, tx, query)
+		if err != nil {
+			return nil, explainQueryError(err, statementTimeout)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Query is a query with the values of its positional parameters
+type Query struct {
+	SQL  string
+	Args []any
+	// an optional query runs in a savepoint, and if it fails its result is nil instead of an error,
+	// e.g. for views of extensions that may not be installed
+	Optional bool
+}
+
+func runOptionalQuery(ctx context.Context, tx pgx.Tx, query Query) (*QueryResult, error) {
+	if !query.Optional {
+		return runQuery(ctx, tx, query)
+	}
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := runQuery(ctx, savepoint, query)
+	if err != nil {
+		return nil, savepoint.Rollback(ctx)
+	}
+	return result, savepoint.Commit(ctx)
+}
+
+func runQuery(ctx context.Context, tx pgx.Tx, query Query) (*QueryResult, error) {
+	rows, err := tx.Query(ctx, query.SQL, query.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return ScanResult(rows)
+}
+
+// beginReadOnly connects to the database and begins a read-only transaction with the statement timeout
+func beginReadOnly(ctx context.Context, cred *ConnectionInfo, statementTimeout time.Duration) (*pgxpool.Pool, pgx.Tx, error) {
+	return beginTx(ctx, cred, statementTimeout, pgx.ReadOnly)
+}
+
+// beginTx connects to the database and begins a transaction with the access mode and the statement timeout
+func beginTx(ctx context.Context, cred *ConnectionInfo, statementTimeout time.Duration, accessMode pgx.TxAccessMode) (*pgxpool.Pool, pgx.Tx, error) {
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	dbpool, err := NewPostgresPool(connectCtx, cred)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// printed to stderr, so the result can be piped when it is printed as JSON or CSV
+	fmt.Fprintln(os.Stderr, "Connected to the database successfully!")
+
+	tx, err := dbpool.BeginTx(ctx, pgx.TxOptions{AccessMode: accessMode})
+	if err != nil {
+		dbpool.Close()
+		return nil, nil, err
+	}
+
+	// set_config with is_local applies the timeout to this transaction only
+	_, err = tx.Exec(ctx, "select set_config('statement_timeout', $1, true)", strconv.FormatInt(statementTimeout.Milliseconds(), 10))
+	if err != nil {
+		tx.Rollback(ctx)
+		dbpool.Close()
+		return nil, nil, err
+	}
+	return dbpool, tx, nil
+}
+
+func openRowSource(ctx context.Context, tx pgx.Tx, query string, options StreamOptions, args []any) (rowSource, error) {
+	if err := CheckQueryKeyword(query); err != nil {
+		return nil, err
+	}
+
+	// Exec would send the DECLARE over the simple protocol when there are no args, which runs every statement of the query,
+	// so a query like "select 1; commit; drop table t" would end the read-only transaction and run the rest outside of it.
+	// Query always uses the extended protocol, where PostgreSQL rejects a query with several statements
+	declared, err := tx.Query(ctx, "DECLARE "+streamCursor+" NO SCROLL CURSOR FOR "+strings.TrimRight(strings.TrimSpace(query), ";"), args...)
+	if err != nil {
+		return nil, err
+	}
+	declared.Close()
+	if err = declared.Err(); err != nil {
+		return nil, err
+	}
+	batchSize := streamBatchSize
+	if options.PageSize > 0 {
+		batchSize = options.PageSize
+	}
+	if options.Limit > 0 {
+		// one more row than the limit is fetched, to know if the result was cut
+		batchSize = min(batchSize, options.Limit+1)
+	}
+	source := &cursorRowSource{ctx: ctx, tx: tx, batchSize: batchSize}
+	return source, source.fetch()
+}
+
+// cursorRowSource fetches the rows from the cursor in batches
+type cursorRowSource struct {
+	ctx       context.Context
+	tx        pgx.Tx
+	batchSize int
+	rows      pgx.Rows
+	fetched   int
+	done      bool
+}
+
+func (source *cursorRowSource) fetch() error {
+	rows, err := source.tx.Query(source.ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", source.batchSize, streamCursor))
+	if err != nil {
+		return err
+	}
+	source.rows = rows
+	source.fetched = 0
+	return nil
+}
+
+func (source *cursorRowSource) columns() []string {
+	return fieldNames(source.rows)
+}
+
+func (source *cursorRowSource) next() ([]any, bool, error) {
+	if source.done {
+		return nil, false, nil
+	}
+	if !source.rows.Next() {
+		source.rows.Close()
+		if source.rows.Err() != nil {
+			return nil, false, source.rows.Err()
+		}
+		// a batch with fewer rows than requested is the last one
+		if source.fetched < source.batchSize {
+			source.done = true
+			return nil, false, nil
+		}
+		err := source.fetch()
+		if err != nil {
+			return nil, false, err
+		}
+		return source.next()
+	}
+	source.fetched++
+	return scanValues(source.rows)
+}
+
+func (source *cursorRowSource) close() {
+	if source.rows != nil {
+		source.rows.Close()
+	}
+}
+
+func fieldNames(rows pgx.Rows) []string {
+	var names []string
+	for _, field := range rows.FieldDescriptions() {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func scanValues(rows pgx.Rows) ([]any, bool, error) {
+	values, err := rows.Values()
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to scan the row: %v", err)
+	}
+	for i := range values {
+		values[i] = normalizeValue(values[i])
+	}
+	return values, true, nil
+}
+
+// CheckQueryKeyword returns an error if the first keyword of the query is not one of QueryKeywords
+func CheckQueryKeyword(query string) error {
+	keyword := FirstKeyword(query)
+	if !slices.Contains(QueryKeywords, keyword) {
+		return fmt.Errorf("only %s queries are allowed, not '%s'", strings.Join(QueryKeywords, ", "), keyword)
+	}
+	return nil
+}
+
+// FirstKeyword returns the first word of the statement in upper case, skipping the comments before it
+func FirstKeyword(statement string) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		if strings.HasPrefix(statement, "--") {
+			_, statement, _ = strings.Cut(statement, "\n")
+		} else if strings.HasPrefix(statement, "/*") {
+			_, statement, _ = strings.Cut(statement, "*/")
+		} else {
+			break
+		}
+	}
+	end := strings.IndexFunc(statement, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end == -1 {
+		end = len(statement)
+	}
+	return strings.ToUpper(statement[:end])
+}
@@ -0,0 +1,495 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/go-redis/redis"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"goli-cli/utils/outputUtils"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the Redis console history is kept in the goli folder next to the SQL history
+const redisHistoryFile = "redisHistory"
+
+const (
+	// the keys SCAN is asked for in every call, and the keys \scan lists at most
+	redisScanCount = 1000
+	redisScanLimit = 1000
+	// the elements of a hash, list, set, sorted set or stream \get prints at most
+	redisValueLimit = 100
+)
+
+const redisConsoleHelp = `  \scan [PATTERN] [TYPE]  list the keys that match the pattern, of the type only if it is set, with their TTLs
+  \get KEY                print the value of the key by its type, with its TTL
+  \allow                  toggle allowing the dangerous commands, e.g. FLUSHALL and KEYS, after a confirmation of each one
+  \?                      show this help
+  \q                      quit the console
+Any other line is sent to Redis as a command, arguments with spaces can be quoted, e.g. SET greeting "hello world".`
+
+// the commands that are refused unless they are allowed with \allow and confirmed, with the reason
+var dangerousRedisCommands = map[string]string{
+	"FLUSHALL": "it deletes the keys of all the databases",
+	"FLUSHDB":  "it deletes all the keys of the database",
+	"KEYS":     `it blocks the server while it goes over all the keys, use \scan instead`,
+	"SHUTDOWN": "it stops the server",
+	"DEBUG":    "it can block or crash the server",
+	"SWAPDB":   "it swaps the keys of two databases",
+	"CONFIG":   "it changes the configuration of the server",
+	// scripts and functions can run any of the commands above
+	"EVAL":       "its script can run any command",
+	"EVAL_RO":    "its script can run any read command, e.g. KEYS",
+	"EVALSHA":    "its script can run any command",
+	"EVALSHA_RO": "its script can run any read command, e.g. KEYS",
+	"FCALL":      "its function can run any command",
+	"FCALL_RO":   "its function can run any read command, e.g. KEYS",
+	"SCRIPT":     "it loads or flushes the scripts of the server",
+	"FUNCTION":   "it loads or deletes the functions of the server",
+}
+
+// the commands that keep the connection waiting for messages, which the console cannot show
+var unsupportedRedisCommands = []string{"MONITOR", "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "SYNC", "PSYNC"}
+
+type redisConsole struct {
+	rdb            *redis.Client
+	allowDangerous bool
+}
+
+// OpenRedisConsole runs an interactive Redis console on the local credentials until the user quits it
+func OpenRedisConsole(cred *ConnectionInfo) error {
+	// a single connection, so SELECT and MULTI apply to the following commands
+	rdb := newRedisClient(cred, 1)
+	defer rdb.Close()
+	if err := rdb.Ping().Err(); err != nil {
+		return err
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:                 "redis> ",
+		HistoryFile:            redisHistoryFile,
+		DisableAutoSaveHistory: true,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              `\q`,
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	// Ctrl+C while a command runs is not handled by readline, it is ignored instead of interrupting the process,
+	// since the commands are limited by the read timeout of the client
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	console := &redisConsole{rdb: rdb}
+	fmt.Println("Connected to Redis, type \\? for help and \\q to quit.")
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		rl.SaveHistory(trimmed)
+		if trimmed == `\q` {
+			return nil
+		}
+		args, err := splitRedisArgs(trimmed)
+		if err != nil {
+			outputUtils.PrintErrorMessage(err.Error())
+			continue
+		}
+		if strings.HasPrefix(args[0], `\`) {
+			console.runMetaCommand(args)
+			continue
+		}
+		console.execute(args)
+	}
+}
+
+func (console *redisConsole) runMetaCommand(args []string) {
+	switch args[0] {
+	case `\scan`:
+		if len(args) > 3 {
+			outputUtils.PrintErrorMessage(`usage: \scan [PATTERN] [TYPE]`)
+			return
+		}
+		pattern, keyType := "*", ""
+		if len(args) > 1 {
+			pattern = args[1]
+		}
+		if len(args) > 2 {
+			keyType = strings.ToLower(args[2])
+		}
+		console.scan(pattern, keyType)
+	case `\get`:
+		if len(args) != 2 {
+			outputUtils.PrintErrorMessage(`usage: \get KEY`)
+			return
+		}
+		console.printKey(args[1])
+	case `\allow`:
+		console.allowDangerous = !console.allowDangerous
+		if console.allowDangerous {
+			outputUtils.PrintWarningMessage("Dangerous commands are allowed, each of them asks for a confirmation.")
+		} else {
+			fmt.Println("Dangerous commands are refused.")
+		}
+	case `\?`:
+		fmt.Println(redisConsoleHelp)
+	default:
+		outputUtils.PrintErrorMessage(fmt.Sprintf(`invalid command %s, type \? for help`, args[0]))
+	}
+}
+
+func (console *redisConsole) execute(args []string) {
+	name := strings.ToUpper(args[0])
+	for _, unsupported := range unsupportedRedisCommands {
+		if name == unsupported {
+			outputUtils.PrintErrorMessage(name, "is not supported by the console")
+			return
+		}
+	}
+	// CONFIG GET only reads the configuration
+	isConfigGet := name == "CONFIG" && len(args) > 1 && strings.ToUpper(args[1]) == "GET"
+	if reason, dangerous := dangerousRedisCommands[name]; dangerous && !isConfigGet {
+		if !console.allowDangerous {
+			outputUtils.PrintErrorMessage(fmt.Sprintf(`%s is refused because %s, type \allow to allow it`, name, reason))
+			return
+		}
+		outputUtils.PrintWarningMessage(fmt.Sprintf("%s is dangerous because %s.", name, reason))
+		if !utils.PresentSecurityQuestion() {
+			return
+		}
+	}
+
+	commandArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		commandArgs[i] = arg
+	}
+	reply, err := console.rdb.Do(commandArgs...).Result()
+	if err != nil && err != redis.Nil {
+		outputUtils.PrintErrorMessage("(error)", err.Error())
+		return
+	}
+	fmt.Println(strings.Join(formatRedisReply(reply), "\n"))
+}
+
+// scan lists the keys that match the pattern with their types and TTLs, SCAN is used so the server is not blocked
+func (console *redisConsole) scan(pattern, keyType string) {
+	rows := [][]string{{"Key", "Type", "TTL"}}
+	var cursor uint64
+	truncated := false
+	for {
+		keys, next, err := console.rdb.Scan(cursor, pattern, redisScanCount).Result()
+		if err != nil {
+			outputUtils.PrintErrorMessage("(error)", err.Error())
+			return
+		}
+		types, ttls, err := console.typesAndTTLs(keys)
+		if err != nil {
+			outputUtils.PrintErrorMessage("(error)", err.Error())
+			return
+		}
+		for i, key := range keys {
+			if keyType != "" && types[i] != keyType {
+				continue
+			}
+			if len(rows) > redisScanLimit {
+				truncated = true
+				break
+			}
+			rows = append(rows, []string{key, types[i], formatTTL(ttls[i])})
+		}
+		cursor = next
+		if cursor == 0 || truncated {
+			break
+		}
+	}
+
+	if len(rows) == 1 {
+		fmt.Println("(no keys)")
+		return
+	}
+	sort.Slice(rows[1:], func(i, j int) bool { return rows[i+1][0] < rows[j+1][0] })
+	PrintQueryResult(rows)
+	if truncated {
+		fmt.Println(color.HiBlackString("(showing the first %d keys, refine the pattern to see the others)", redisScanLimit))
+	} else {
+		fmt.Printf("(%d keys)\n", len(rows)-1)
+	}
+}
+
+// typesAndTTLs reads the types and TTLs of the keys in a single round trip
+func (console *redisConsole) typesAndTTLs(keys []string) ([]string, []time.Duration, error) {
+	if len(keys) == 0 {
+		return nil, nil, nil
+	}
+	pipe := console.rdb.Pipeline()
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		typeCmds[i] = pipe.Type(key)
+		ttlCmds[i] = pipe.TTL(key)
+	}
+	if _, err := pipe.Exec(); err != nil {
+		return nil, nil, err
+	}
+	types := make([]string, len(keys))
+	ttls := make([]time.Duration, len(keys))
+	for i := range keys {
+		types[i] = typeCmds[i].Val()
+		ttls[i] = ttlCmds[i].Val()
+	}
+	return types, ttls, nil
+}
+
+// printKey prints the value of the key by its type, the elements of the collections up to redisValueLimit
+func (console *redisConsole) printKey(key string) {
+	types, ttls, err := console.typesAndTTLs([]string{key})
+	if err != nil {
+		outputUtils.PrintErrorMessage("(error)", err.Error())
+		return
+	}
+	if types[0] == "none" {
+		fmt.Println("(nil)")
+		return
+	}
+	fmt.Println(color.HiCyanString(key), color.HiBlackString("(%s, %s)", types[0], formatTTL(ttls[0])))
+
+	var rows [][]string
+	var total int64
+	switch types[0] {
+	case "string":
+		value, err := console.rdb.Get(key).Result()
+		if err != nil {
+			outputUtils.PrintErrorMessage("(error)", err.Error())
+			return
+		}
+		fmt.Println(prettyJSON(value))
+		return
+	case "hash":
+		total, err = console.rdb.HLen(key).Result()
+		if err == nil {
+			var pairs []string
+			pairs, err = console.scanElements(key, console.rdb.HScan, 2*redisValueLimit)
+			rows = [][]string{{"Field", "Value"}}
+			for i := 0; i+1 < len(pairs); i += 2 {
+				rows = append(rows, []string{pairs[i], prettyJSON(pairs[i+1])})
+			}
+			sort.Slice(rows[1:], func(i, j int) bool { return rows[i+1][0] < rows[j+1][0] })
+		}
+	case "list":
+		total, err = console.rdb.LLen(key).Result()
+		if err == nil {
+			var values []string
+			values, err = console.rdb.LRange(key, 0, redisValueLimit-1).Result()
+			rows = [][]string{{"Index", "Value"}}
+			for i, value := range values {
+				rows = append(rows, []string{strconv.Itoa(i), prettyJSON(value)})
+			}
+		}
+	case "set":
+		total, err = console.rdb.SCard(key).Result()
+		if err == nil {
+			var members []string
+			members, err = console.scanElements(key, console.rdb.SScan, redisValueLimit)
+			sort.Strings(members)
+			rows = [][]string{{"Member"}}
+			for _, member := range members {
+				rows = append(rows, []string{member})
+			}
+		}
+	case "zset":
+		total, err = console.rdb.ZCard(key).Result()
+		if err == nil {
+			var members []redis.Z
+			members, err = console.rdb.ZRangeWithScores(key, 0, redisValueLimit-1).Result()
+			rows = [][]string{{"Score", "Member"}}
+			for _, member := range members {
+				rows = append(rows, []string{strconv.FormatFloat(member.Score, 'f', -1, 64), fmt.Sprint(member.Member)})
+			}
+		}
+	case "stream":
+		total, err = console.rdb.XLen(key).Result()
+		if err == nil {
+			var messages []redis.XMessage
+			messages, err = console.rdb.XRangeN(key, "-", "+", redisValueLimit).Result()
+			rows = [][]string{{"ID", "Values"}}
+			for _, message := range messages {
+				// the fields are sorted by json.Marshal
+				values, _ := json.Marshal(message.Values)
+				rows = append(rows, []string{message.ID, string(values)})
+			}
+		}
+	default:
+		fmt.Println("the values of the type", types[0], "cannot be printed, use the Redis commands of the type")
+		return
+	}
+	if err != nil {
+		outputUtils.PrintErrorMessage("(error)", err.Error())
+		return
+	}
+
+	if len(rows) > 1 {
+		PrintQueryResult(rows)
+	}
+	if int64(len(rows)-1) < total {
+		fmt.Println(color.HiBlackString("(showing %d of %d elements)", len(rows)-1, total))
+	} else {
+		fmt.Printf("(%d elements)\n", total)
+	}
+}
+
+// scanElements reads at most limit elements of the hash or set with HSCAN or SSCAN, the fields and values of a hash are both elements
+func (console *redisConsole) scanElements(key string, scan func(key string, cursor uint64, match string, count int64) *redis.ScanCmd, limit int) ([]string, error) {
+	var elements []string
+	var cursor uint64
+	for {
+		page, next, err := scan(key, cursor, "", int64(limit)).Result()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, page...)
+		cursor = next
+		if len(elements) >= limit {
+			return elements[:limit], nil
+		}
+		if cursor == 0 {
+			return elements, nil
+		}
+	}
+}
+
+// formatRedisReply formats the reply of a command like redis-cli, with the elements of arrays numbered
+func formatRedisReply(reply interface{}) []string {
+	switch value := reply.(type) {
+	case nil:
+		return []string{"(nil)"}
+	case int64:
+		return []string{fmt.Sprintf("(integer) %d", value)}
+	case string:
+		if value == "" {
+			return []string{`""`}
+		}
+		return []string{value}
+	case []interface{}:
+		if len(value) == 0 {
+			return []string{"(empty array)"}
+		}
+		var lines []string
+		width := len(strconv.Itoa(len(value)))
+		for i, element := range value {
+			prefix := fmt.Sprintf("%*d) ", width, i+1)
+			for j, line := range formatRedisReply(element) {
+				if j == 0 {
+					lines = append(lines, prefix+line)
+				} else {
+					lines = append(lines, strings.Repeat(" ", len(prefix))+line)
+				}
+			}
+		}
+		return lines
+	}
+	return []string{fmt.Sprint(reply)}
+}
+
+// formatTTL formats the TTL of a key, the TTL of keys without an expiry is -1 seconds
+func formatTTL(ttl time.Duration) string {
+	if ttl < 0 {
+		return "no expiry"
+	}
+	return ttl.String()
+}
+
+// prettyJSON indents the value if it is a JSON object or array, other values are returned as they are
+func prettyJSON(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(trimmed), "", "  "); err != nil {
+		return value
+	}
+	return indented.String()
+}
+
+// splitRedisArgs splits the command line on spaces like redis-cli, arguments can be quoted with double quotes,
+// which support escapes like \n and \", or with single quotes, which are taken literally
+func splitRedisArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '"' || r == '\'':
+			quote := r
+			inArg = true
+			i++
+			for ; i < len(runes) && runes[i] != quote; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					next := runes[i+1]
+					switch {
+					case quote == '\'' && next == '\'':
+						current.WriteRune('\'')
+					case quote == '\'':
+						current.WriteRune('\\')
+						continue
+					case next == 'n':
+						current.WriteRune('\n')
+					case next == 't':
+						current.WriteRune('\t')
+					case next == 'r':
+						current.WriteRune('\r')
+					default:
+						current.WriteRune(next)
+					}
+					i++
+					continue
+				}
+				current.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unbalanced quotes")
+			}
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"golang.org/x/crypto/ssh"
+	"goli-cli/utils"
+	"goli-cli/utils/applicationsUtils"
+	"goli-cli/utils/outputUtils"
+	"strings"
+)
+
+// OpenSshClient opens an SSH connection to the given instance of the app's web process through the CF ssh proxy.
+// if SSH is disabled for the app, the user is offered to enable it (which requires a rolling restart).
+func OpenSshClient(cf *client.Client, appGUID, appName string, index int) (*ssh.Client, error) {
+	domain := utils.ExtractDomain(cf.Config.ApiURL(""))
+	server := fmt.Sprintf("ssh.cf.%s:2222", domain)
+
+	process, err := cf.Processes.First(context.Background(), &client.ProcessListOptions{
+		AppGUIDs: client.Filter{Values: []string{appGUID}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	user := fmt.Sprintf("cf:%s/%d", process.GUID, index)
+	password, err := cf.SSHCode(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(password),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	sshClient, err := ssh.Dial("tcp", server, config)
+	if err == nil {
+		return sshClient, nil
+	}
+	if !strings.Contains(err.Error(), "unable to authenticate") {
+		return nil, err
+	}
+
+	res, internalErr := cf.Processes.GetStatsForApp(context.Background(), appGUID, "web")
+	if internalErr != nil {
+		return nil, internalErr
+	}
+	if index >= len(res.Stats) {
+		return nil, fmt.Errorf("app has only %d instances, index %d does not exist", len(res.Stats), index)
+	}
+	for _, instance := range res.Stats {
+		if instance.State != "RUNNING" {
+			return nil, errors.New("app is not running... please start the app and try again")
+		}
+	}
+	outputUtils.PrintWarningMessage("you are not authorized to perform the requested action (maybe SSH access is off?)")
+	ans := utils.QuestionPrompt("Do you want to enable ssh?")
+	if !ans {
+		return nil, err
+	}
+	err = applicationsUtils.EnableAppSsh(cf, appGUID)
+	if err != nil {
+		return nil, err
+	}
+	err = applicationsUtils.RestartAppRolling(cf, appGUID, appName)
+	if err != nil {
+		return nil, err
+	}
+	return OpenSshClient(cf, appGUID, appName, index)
+}
+
+// RunSshCommand runs a single command in the container and returns its combined output.
+func RunSshCommand(sshClient *ssh.Client, command string) (string, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	output, err := session.CombinedOutput(command)
+	return strings.TrimSpace(string(output)), err
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"gopkg.in/yaml.v3"
 	"time"
 )
 
@@ -40,6 +41,69 @@ type ConnectionInfo struct {
 	Port     string `json:"port"`
 }
 
+type PortMapping struct {
+	LocalPort  string
+	RemoteHost string
+	RemotePort string
+}
+
+type Manifest struct {
+	Applications []*ManifestApp `yaml:"applications"`
+}
+
+type ManifestApp struct {
+	Name       string             `yaml:"name"`
+	Stack      string             `yaml:"stack,omitempty"`
+	Buildpacks []string           `yaml:"buildpacks,omitempty"`
+	Env        map[string]string  `yaml:"env,omitempty"`
+	Routes     []*ManifestRoute   `yaml:"routes,omitempty"`
+	Services   []ManifestService  `yaml:"services,omitempty"`
+	Processes  []*ManifestProcess `yaml:"processes,omitempty"`
+
+	// the attributes of the web process can also be set at the app level
+	ManifestProcess `yaml:",inline"`
+	// deprecated, replaced by buildpacks
+	Buildpack string `yaml:"buildpack,omitempty"`
+}
+
+type ManifestRoute struct {
+	Route    string `yaml:"route"`
+	Protocol string `yaml:"protocol,omitempty"`
+}
+
+// ManifestService is the name of a bound service, in a manifest it can also be written as a map with parameters
+type ManifestService string
+
+func (service *ManifestService) UnmarshalYAML(node *yaml.Node) error {
+	var name string
+	var err error
+	if node.Kind == yaml.MappingNode {
+		var binding struct {
+			Name string `yaml:"name"`
+		}
+		err = node.Decode(&binding)
+		name = binding.Name
+	} else {
+		err = node.Decode(&name)
+	}
+	*service = ManifestService(name)
+	return err
+}
+
+type ManifestProcess struct {
+	Type                             string `yaml:"type,omitempty"`
+	Command                          string `yaml:"command,omitempty"`
+	Instances                        *int   `yaml:"instances,omitempty"`
+	Memory                           string `yaml:"memory,omitempty"`
+	DiskQuota                        string `yaml:"disk_quota,omitempty"`
+	HealthCheckType                  string `yaml:"health-check-type,omitempty"`
+	HealthCheckHTTPEndpoint          string `yaml:"health-check-http-endpoint,omitempty"`
+	HealthCheckInvocationTimeout     int    `yaml:"health-check-invocation-timeout,omitempty"`
+	Timeout                          int    `yaml:"timeout,omitempty"`
+	ReadinessHealthCheckType         string `yaml:"readiness-health-check-type,omitempty"`
+	ReadinessHealthCheckHTTPEndpoint string `yaml:"readiness-health-check-http-endpoint,omitempty"`
+}
+
 type CfUser struct {
 	Email string `json:"user_name"`
 	Role  string `json:"role"`
@@ -143,6 +207,10 @@ type LocalConfig struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
 	} `json:"dbCredentials"`
+	DbClients struct {
+		Postgres string `json:"postgres,omitempty"`
+		Redis    string `json:"redis,omitempty"`
+	} `json:"dbClients"`
 }
 type UserInfo struct {
 	Email string `json:"mail"`
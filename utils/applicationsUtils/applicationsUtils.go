@@ -11,8 +11,15 @@ import (
 	"time"
 )
 
-func CreateDeployment(cf *client.Client, appGUID string, isRestage bool, dropletGUID string) error {
-	fmt.Println("creating the deployment")
+// ProgressReporter receives the progress messages of the long-running app flows
+type ProgressReporter func(message string)
+
+func PrintProgress(message string) {
+	fmt.Println(message)
+}
+
+func CreateDeployment(cf *client.Client, appGUID string, isRestage bool, dropletGUID string, report ProgressReporter) error {
+	report("creating the deployment")
 	deploymentResource := resource.DeploymentCreate{
 		Relationships: resource.AppRelationship{
 			App: resource.ToOneRelationship{
@@ -30,7 +37,7 @@ func CreateDeployment(cf *client.Client, appGUID string, isRestage bool, droplet
 	if err != nil {
 		return err
 	}
-	fmt.Println("Waiting for deployment to finish...")
+	report("Waiting for deployment to finish...")
 	isDeployed := "DEPLOYING"
 	for isDeployed == "DEPLOYING" {
 		time.Sleep(5 * time.Second)
@@ -39,13 +46,13 @@ func CreateDeployment(cf *client.Client, appGUID string, isRestage bool, droplet
 			return err
 		}
 		isDeployed = deploymentItem.Status.Reason
-		fmt.Println("deployment status: ", isDeployed)
+		report("deployment status: " + isDeployed)
 	}
-	fmt.Println("deployment finished")
+	report("deployment finished")
 	return nil
 }
 
-func BuildPackage(cf *client.Client, appGUID string) (string, error) {
+func BuildPackage(cf *client.Client, appGUID string, report ProgressReporter) (string, error) {
 	packageItem, err := cf.Packages.FirstForApp(context.Background(), appGUID, &client.PackageListOptions{
 		ListOptions: &client.ListOptions{
 			OrderBy: "created_at",
@@ -55,7 +62,7 @@ func BuildPackage(cf *client.Client, appGUID string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	fmt.Println("creating the build")
+	report("creating the build")
 	build, err := cf.Builds.Create(context.Background(), &resource.BuildCreate{
 		Package: resource.Relationship{
 			GUID: packageItem.GUID,
@@ -73,22 +80,31 @@ func BuildPackage(cf *client.Client, appGUID string) (string, error) {
 			return "", err
 		}
 		isStage = buildItem.State
-		fmt.Println("build status: ", isStage)
+		report("build status: " + string(isStage))
 	}
-	fmt.Println("build finished")
+	report("build finished")
 	return buildItem.Droplet.GUID, nil
 }
 
-func CheckAppStatus(cf *client.Client, appGUID string, appName string) error {
+// WaitForAppStatus waits for the app to finish starting and returns its final state
+func WaitForAppStatus(cf *client.Client, appGUID string, report ProgressReporter) (string, error) {
 	isRunning := "STARTING"
 	for isRunning == "STARTING" {
 		time.Sleep(2 * time.Second)
 		app, err := cf.Processes.GetStatsForApp(context.Background(), appGUID, "web")
 		if err != nil {
-			return err
+			return "", err
 		}
 		isRunning = app.Stats[0].State
-		fmt.Println("app status: ", isRunning)
+		report("app status: " + isRunning)
+	}
+	return isRunning, nil
+}
+
+func CheckAppStatus(cf *client.Client, appGUID string, appName string) error {
+	isRunning, err := WaitForAppStatus(cf, appGUID, PrintProgress)
+	if err != nil {
+		return err
 	}
 	if isRunning == "CRASHED" {
 		fmt.Println("app crashed!")
@@ -123,7 +139,7 @@ func EnableAppSsh(cf *client.Client, appGUID string) error {
 
 func RestartAppRolling(cf *client.Client, appGUID, appName string) error {
 	fmt.Println("restarting application - ", color.HiCyanString(appName))
-	err := CreateDeployment(cf, appGUID, false, "")
+	err := CreateDeployment(cf, appGUID, false, "", PrintProgress)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,138 @@
+package applicationsUtils
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	batchPending = "PENDING"
+	batchRunning = "RUNNING"
+	batchSuccess = "SUCCEEDED"
+	batchFailed  = "FAILED"
+)
+
+type batchStatus struct {
+	state     string
+	message   string
+	startedAt time.Time
+	duration  time.Duration
+}
+
+// BatchProgress tracks the progress of an operation that runs on several apps in parallel
+type BatchProgress struct {
+	lock          sync.Mutex
+	appNames      []string
+	statuses      map[string]*batchStatus
+	renderedLines int
+	// set if the output is a terminal, where the table can be redrawn in place
+	live bool
+}
+
+func NewBatchProgress(appNames []string) *BatchProgress {
+	statuses := make(map[string]*batchStatus, len(appNames))
+	for _, appName := range appNames {
+		statuses[appName] = &batchStatus{state: batchPending}
+	}
+	return &BatchProgress{appNames: appNames, statuses: statuses, live: readline.IsTerminal(int(os.Stdout.Fd()))}
+}
+
+// Live returns true if the output is a terminal, where Render replaces the previously rendered table
+func (p *BatchProgress) Live() bool {
+	return p.live
+}
+
+func (p *BatchProgress) Start(appName string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.statuses[appName].state = batchRunning
+	p.statuses[appName].startedAt = time.Now()
+}
+
+// Reporter returns a ProgressReporter that records the last message of the app
+func (p *BatchProgress) Reporter(appName string) ProgressReporter {
+	return func(message string) {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.statuses[appName].message = message
+	}
+}
+
+func (p *BatchProgress) Finish(appName string, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	status := p.statuses[appName]
+	status.duration = time.Since(status.startedAt).Round(time.Second)
+	if err != nil {
+		status.state = batchFailed
+		status.message = err.Error()
+		return
+	}
+	status.state = batchSuccess
+	status.message = "app is running"
+}
+
+// Render prints the progress table, replacing the previously rendered table when the output is a terminal
+func (p *BatchProgress) Render() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	buffer := &bytes.Buffer{}
+	table := tablewriter.NewWriter(buffer)
+	table.SetHeader([]string{"App", "State", "Duration", "Progress"})
+	for _, appName := range p.appNames {
+		status := p.statuses[appName]
+		duration := ""
+		if status.state == batchRunning {
+			duration = time.Since(status.startedAt).Round(time.Second).String()
+		} else if status.state != batchPending {
+			duration = status.duration.String()
+		}
+		table.Append([]string{appName, colorBatchState(status.state), duration, status.message})
+	}
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(80)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+
+	if p.renderedLines > 0 && p.live {
+		// move the cursor to the start of the previous table and clear it
+		fmt.Printf("\033[%dA\033[J", p.renderedLines)
+	}
+	fmt.Print(buffer.String())
+	p.renderedLines = strings.Count(buffer.String(), "\n")
+}
+
+// Failed returns the names of the apps the operation failed on
+func (p *BatchProgress) Failed() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var failed []string
+	for _, appName := range p.appNames {
+		if p.statuses[appName].state == batchFailed {
+			failed = append(failed, appName)
+		}
+	}
+	return failed
+}
+
+func colorBatchState(state string) string {
+	switch state {
+	case batchSuccess:
+		return color.GreenString(state)
+	case batchFailed:
+		return color.RedString(state)
+	case batchRunning:
+		return color.YellowString(state)
+	}
+	return color.HiBlackString(state)
+}
@@ -0,0 +1,260 @@
+package applicationsUtils
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// progressCounter counts the transferred bytes and prints them periodically on the same line
+type progressCounter struct {
+	total int64
+	files int
+	stop  chan bool
+}
+
+func newProgressCounter() *progressCounter {
+	counter := &progressCounter{stop: make(chan bool)}
+	go func() {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-counter.stop:
+				return
+			case <-ticker.C:
+				fmt.Printf("\rtransferred %s", formatBytes(atomic.LoadInt64(&counter.total)))
+			}
+		}
+	}()
+	return counter
+}
+
+func (p *progressCounter) Write(b []byte) (int, error) {
+	atomic.AddInt64(&p.total, int64(len(b)))
+	return len(b), nil
+}
+
+func (p *progressCounter) finish(start time.Time) {
+	p.stop <- true
+	fmt.Printf("\rtransferred %s\n", formatBytes(p.total))
+	fmt.Printf("copied %s files (%s) in %s\n", color.HiCyanString("%d", p.files), formatBytes(p.total), time.Since(start).Round(time.Millisecond))
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ShellQuote quotes a value so it can be safely passed as a single argument to the container shell
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+func isRemoteDir(sshClient *ssh.Client, remotePath string) bool {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return false
+	}
+	defer session.Close()
+	return session.Run("test -d "+ShellQuote(remotePath)) == nil
+}
+
+func remotePathExists(sshClient *ssh.Client, remotePath string) bool {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return false
+	}
+	defer session.Close()
+	return session.Run("test -e "+ShellQuote(remotePath)) == nil
+}
+
+// DownloadFromContainer copies a file or a directory (when recursive) from the container to the local machine.
+// the remote path is packed with tar inside the container and extracted locally while streaming.
+func DownloadFromContainer(sshClient *ssh.Client, remotePath, localPath string, recursive bool) error {
+	remotePath = strings.TrimSuffix(remotePath, "/")
+	if !remotePathExists(sshClient, remotePath) {
+		return fmt.Errorf("%s does not exist in the container", remotePath)
+	}
+	if isRemoteDir(sshClient, remotePath) && !recursive {
+		return fmt.Errorf("%s is a directory (use --recursive to copy directories)", remotePath)
+	}
+
+	// like cp - copying into an existing directory keeps the source name, otherwise the source is renamed
+	targetRoot := localPath
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		targetRoot = filepath.Join(localPath, path.Base(remotePath))
+	}
+
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	session.Stderr = &stderr
+
+	command := fmt.Sprintf("tar -cf - -C %s %s", ShellQuote(path.Dir(remotePath)), ShellQuote(path.Base(remotePath)))
+	if err = session.Start(command); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	counter := newProgressCounter()
+	reader := tar.NewReader(io.TeeReader(stdout, counter))
+	rootName := path.Base(remotePath)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			counter.finish(start)
+			return err
+		}
+		relative := strings.TrimPrefix(strings.TrimPrefix(path.Clean(header.Name), rootName), "/")
+		if strings.HasPrefix(relative, "..") {
+			continue
+		}
+		target := filepath.Join(targetRoot, filepath.FromSlash(relative))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeLocalFile(target, reader, os.FileMode(header.Mode).Perm())
+			counter.files++
+		default:
+			// links and special files are not copied
+			continue
+		}
+		if err != nil {
+			counter.finish(start)
+			return err
+		}
+	}
+	counter.finish(start)
+
+	if err = session.Wait(); err != nil {
+		return errors.New("remote tar failed: " + strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func writeLocalFile(target string, content io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, content)
+	return err
+}
+
+// UploadToContainer copies a local file or a directory (when recursive) into the container.
+// the local path is packed with tar locally and extracted inside the container while streaming.
+func UploadToContainer(sshClient *ssh.Client, localPath, remotePath string, recursive bool) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory (use --recursive to copy directories)", localPath)
+	}
+
+	// like cp - copying into an existing directory keeps the source name, otherwise the source is renamed
+	remoteDir, rootName := path.Dir(remotePath), path.Base(remotePath)
+	if strings.HasSuffix(remotePath, "/") || isRemoteDir(sshClient, remotePath) {
+		remoteDir, rootName = strings.TrimSuffix(remotePath, "/"), filepath.Base(localPath)
+	}
+
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	session.Stderr = &stderr
+
+	command := fmt.Sprintf("mkdir -p %[1]s && tar -xf - -C %[1]s", ShellQuote(remoteDir))
+	if err = session.Start(command); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	counter := newProgressCounter()
+	writer := tar.NewWriter(io.MultiWriter(stdin, counter))
+	err = filepath.Walk(localPath, func(file string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fileInfo.IsDir() && !fileInfo.Mode().IsRegular() {
+			// links and special files are not copied
+			return nil
+		}
+		relative, err := filepath.Rel(localPath, file)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(fileInfo, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(rootName, filepath.ToSlash(relative))
+		if err = writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if fileInfo.IsDir() {
+			return nil
+		}
+		counter.files++
+		content, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		_, err = io.Copy(writer, content)
+		return err
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	stdin.Close()
+	counter.finish(start)
+	if err != nil {
+		return err
+	}
+
+	if err = session.Wait(); err != nil {
+		return errors.New("remote tar failed: " + strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
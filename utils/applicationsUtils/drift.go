@@ -0,0 +1,296 @@
+package applicationsUtils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DriftHigh   = "HIGH"
+	DriftMedium = "MEDIUM"
+	DriftLow    = "LOW"
+)
+
+var driftSeverityOrder = map[string]int{DriftHigh: 0, DriftMedium: 1, DriftLow: 2}
+
+type DriftItem struct {
+	Severity string `json:"severity"`
+	Field    string `json:"field"`
+	Manifest string `json:"manifest"`
+	Live     string `json:"live"`
+}
+
+// LoadManifestApp reads the manifest file and returns the app with the given name,
+// a manifest with a single app is used even if the name is different
+func LoadManifestApp(manifestPath, appName string) (*ManifestApp, error) {
+	file, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	err = yaml.Unmarshal(file, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the manifest: %w", err)
+	}
+	for _, app := range manifest.Applications {
+		if app.Name == appName {
+			return app, nil
+		}
+	}
+	if len(manifest.Applications) == 1 {
+		return manifest.Applications[0], nil
+	}
+	return nil, errors.New("the manifest does not contain the application " + appName)
+}
+
+// DetectDrift compares the manifest app with the manifest generated from the live app,
+// only the attributes that are set in the manifest are compared
+func DetectDrift(manifestApp, liveApp *ManifestApp) []*DriftItem {
+	var items []*DriftItem
+	add := func(severity, field, manifestValue, liveValue string) {
+		items = append(items, &DriftItem{Severity: severity, Field: field, Manifest: manifestValue, Live: liveValue})
+	}
+
+	if manifestApp.Stack != "" && manifestApp.Stack != liveApp.Stack {
+		add(DriftMedium, "stack", manifestApp.Stack, liveApp.Stack)
+	}
+	buildpacks := manifestApp.Buildpacks
+	if manifestApp.Buildpack != "" && len(buildpacks) == 0 {
+		buildpacks = []string{manifestApp.Buildpack}
+	}
+	if len(buildpacks) > 0 && strings.Join(buildpacks, ",") != strings.Join(liveApp.Buildpacks, ",") {
+		add(DriftMedium, "buildpacks", strings.Join(buildpacks, ", "), strings.Join(liveApp.Buildpacks, ", "))
+	}
+
+	liveProcesses := make(map[string]*ManifestProcess)
+	for _, process := range liveApp.Processes {
+		liveProcesses[process.Type] = process
+	}
+	for _, process := range manifestProcesses(manifestApp) {
+		liveProcess, ok := liveProcesses[process.Type]
+		if !ok {
+			add(DriftHigh, "processes."+process.Type, "present", "missing")
+			continue
+		}
+		items = append(items, diffProcess(process, liveProcess)...)
+	}
+
+	if len(manifestApp.Routes) > 0 {
+		var manifestRoutes, liveRoutes []string
+		for _, route := range manifestApp.Routes {
+			manifestRoutes = append(manifestRoutes, route.Route)
+		}
+		for _, route := range liveApp.Routes {
+			liveRoutes = append(liveRoutes, route.Route)
+		}
+		missing, extra := diffLists(manifestRoutes, liveRoutes)
+		for _, route := range missing {
+			add(DriftHigh, "routes", route, "missing")
+		}
+		for _, route := range extra {
+			add(DriftMedium, "routes", "missing", route)
+		}
+	}
+
+	var manifestServices, liveServices []string
+	for _, service := range manifestApp.Services {
+		manifestServices = append(manifestServices, string(service))
+	}
+	for _, service := range liveApp.Services {
+		liveServices = append(liveServices, string(service))
+	}
+	missing, extra := diffLists(manifestServices, liveServices)
+	for _, service := range missing {
+		add(DriftHigh, "services", service, "not bound")
+	}
+	for _, service := range extra {
+		add(DriftMedium, "services", "not bound", service)
+	}
+
+	for key, value := range manifestApp.Env {
+		liveValue, ok := liveApp.Env[key]
+		switch {
+		case !ok:
+			add(DriftMedium, "env."+key, maskEnv(key, value), "missing")
+		case strings.Contains(value, "(("):
+			// the value of a placeholder is only known on push
+		case value != liveValue:
+			add(DriftMedium, "env."+key, maskEnv(key, value), maskEnv(key, liveValue))
+		}
+	}
+	for key, value := range liveApp.Env {
+		if _, ok := manifestApp.Env[key]; !ok {
+			add(DriftLow, "env."+key, "missing", maskEnv(key, value))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Severity != items[j].Severity {
+			return driftSeverityOrder[items[i].Severity] < driftSeverityOrder[items[j].Severity]
+		}
+		return items[i].Field < items[j].Field
+	})
+	return items
+}
+
+// manifestProcesses returns the processes of the manifest app, with the app level attributes merged into the web process
+func manifestProcesses(manifestApp *ManifestApp) []*ManifestProcess {
+	var processes []*ManifestProcess
+	var web *ManifestProcess
+	for _, process := range manifestApp.Processes {
+		processCopy := *process
+		processes = append(processes, &processCopy)
+		if process.Type == "web" {
+			web = &processCopy
+		}
+	}
+	appLevel := manifestApp.ManifestProcess
+	if appLevel == (ManifestProcess{}) {
+		return processes
+	}
+	if web == nil {
+		web = &ManifestProcess{Type: "web"}
+		processes = append(processes, web)
+	}
+	if web.Command == "" {
+		web.Command = appLevel.Command
+	}
+	if web.Instances == nil {
+		web.Instances = appLevel.Instances
+	}
+	if web.Memory == "" {
+		web.Memory = appLevel.Memory
+	}
+	if web.DiskQuota == "" {
+		web.DiskQuota = appLevel.DiskQuota
+	}
+	if web.HealthCheckType == "" {
+		web.HealthCheckType = appLevel.HealthCheckType
+	}
+	if web.HealthCheckHTTPEndpoint == "" {
+		web.HealthCheckHTTPEndpoint = appLevel.HealthCheckHTTPEndpoint
+	}
+	if web.HealthCheckInvocationTimeout == 0 {
+		web.HealthCheckInvocationTimeout = appLevel.HealthCheckInvocationTimeout
+	}
+	if web.Timeout == 0 {
+		web.Timeout = appLevel.Timeout
+	}
+	if web.ReadinessHealthCheckType == "" {
+		web.ReadinessHealthCheckType = appLevel.ReadinessHealthCheckType
+	}
+	if web.ReadinessHealthCheckHTTPEndpoint == "" {
+		web.ReadinessHealthCheckHTTPEndpoint = appLevel.ReadinessHealthCheckHTTPEndpoint
+	}
+	return processes
+}
+
+func diffProcess(process, liveProcess *ManifestProcess) []*DriftItem {
+	var items []*DriftItem
+	prefix := "processes." + process.Type + "."
+	add := func(severity, field, manifestValue, liveValue string) {
+		if manifestValue != "" && manifestValue != "0" && manifestValue != liveValue {
+			items = append(items, &DriftItem{Severity: severity, Field: prefix + field, Manifest: manifestValue, Live: liveValue})
+		}
+	}
+
+	if process.Instances != nil && (liveProcess.Instances == nil || *process.Instances != *liveProcess.Instances) {
+		liveInstances := "0"
+		if liveProcess.Instances != nil {
+			liveInstances = strconv.Itoa(*liveProcess.Instances)
+		}
+		items = append(items, &DriftItem{Severity: DriftHigh, Field: prefix + "instances", Manifest: strconv.Itoa(*process.Instances), Live: liveInstances})
+	}
+	add(DriftHigh, "memory", normalizeSize(process.Memory), liveProcess.Memory)
+	add(DriftMedium, "disk_quota", normalizeSize(process.DiskQuota), liveProcess.DiskQuota)
+	add(DriftMedium, "command", process.Command, liveProcess.Command)
+	healthCheckType := process.HealthCheckType
+	if healthCheckType == "none" {
+		// none is the deprecated name of the process health check
+		healthCheckType = "process"
+	}
+	add(DriftMedium, "health-check-type", healthCheckType, liveProcess.HealthCheckType)
+	add(DriftMedium, "health-check-http-endpoint", process.HealthCheckHTTPEndpoint, liveProcess.HealthCheckHTTPEndpoint)
+	add(DriftMedium, "health-check-invocation-timeout", strconv.Itoa(process.HealthCheckInvocationTimeout), strconv.Itoa(liveProcess.HealthCheckInvocationTimeout))
+	add(DriftMedium, "timeout", strconv.Itoa(process.Timeout), strconv.Itoa(liveProcess.Timeout))
+	readinessType := liveProcess.ReadinessHealthCheckType
+	if readinessType == "" {
+		readinessType = "process"
+	}
+	add(DriftMedium, "readiness-health-check-type", process.ReadinessHealthCheckType, readinessType)
+	add(DriftMedium, "readiness-health-check-http-endpoint", process.ReadinessHealthCheckHTTPEndpoint, liveProcess.ReadinessHealthCheckHTTPEndpoint)
+	return items
+}
+
+// normalizeSize converts a manifest size to the notation of the generated manifest so both can be compared
+func normalizeSize(size string) string {
+	if size == "" {
+		return ""
+	}
+	sizeInMB, err := utils.ParseSizeInMB(size)
+	if err != nil {
+		return size
+	}
+	return FormatSizeInMB(sizeInMB)
+}
+
+// diffLists returns the values that are only in the expected list and the values that are only in the actual list
+func diffLists(expected, actual []string) ([]string, []string) {
+	actualSet := make(map[string]bool, len(actual))
+	for _, value := range actual {
+		actualSet[value] = true
+	}
+	expectedSet := make(map[string]bool, len(expected))
+	var missing, extra []string
+	for _, value := range expected {
+		expectedSet[value] = true
+		if !actualSet[value] {
+			missing = append(missing, value)
+		}
+	}
+	for _, value := range actual {
+		if !expectedSet[value] {
+			extra = append(extra, value)
+		}
+	}
+	return missing, extra
+}
+
+func maskEnv(key, value string) string {
+	if isSecretEnv(key) && !strings.Contains(value, "((") {
+		return "*****"
+	}
+	return value
+}
+
+func PrintDrift(items []*DriftItem) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Severity", "Field", "Manifest", "Live"})
+	for _, item := range items {
+		table.Append([]string{colorSeverity(item.Severity), item.Field, item.Manifest, item.Live})
+	}
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(60)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+func colorSeverity(severity string) string {
+	switch severity {
+	case DriftHigh:
+		return color.RedString(severity)
+	case DriftMedium:
+		return color.YellowString(severity)
+	}
+	return color.HiBlackString(severity)
+}
@@ -0,0 +1,127 @@
+package applicationsUtils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"goli-cli/utils/outputUtils"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+var healthCheckTypes = []string{"http", "port", "process"}
+
+type HealthCheckUpdate struct {
+	Type              string
+	Endpoint          string
+	Timeout           int
+	InvocationTimeout int
+}
+
+func GetWebProcess(cf *client.Client, appGUID string) (*resource.Process, error) {
+	return cf.Processes.SingleForApp(context.Background(), appGUID, &client.ProcessListOptions{
+		Types: client.Filter{Values: []string{"web"}},
+	})
+}
+
+func PrintHealthCheck(process *resource.Process) {
+	healthCheck := process.HealthCheck
+	outputUtils.PrintInfoMessage("Health Check:")
+	outputUtils.PrintInfoMessage("  Type: " + healthCheck.Type)
+	if healthCheck.Type == "http" {
+		outputUtils.PrintInfoMessage("  Endpoint: " + stringOrDefault(healthCheck.Data.Endpoint, "/"))
+	}
+	outputUtils.PrintInfoMessage("  Timeout: " + secondsOrDefault(healthCheck.Data.Timeout))
+	outputUtils.PrintInfoMessage("  Invocation Timeout: " + secondsOrDefault(healthCheck.Data.InvocationTimeout))
+	outputUtils.PrintInfoMessage("  Interval: " + secondsOrDefault(healthCheck.Data.Interval))
+
+	readinessCheck := process.ReadinessCheck
+	fmt.Println()
+	outputUtils.PrintInfoMessage("Readiness Check:")
+	outputUtils.PrintInfoMessage("  Type: " + readinessCheck.Type)
+	if readinessCheck.Type == "http" {
+		outputUtils.PrintInfoMessage("  Endpoint: " + stringOrDefault(readinessCheck.Data.Endpoint, "/"))
+	}
+	outputUtils.PrintInfoMessage("  Invocation Timeout: " + secondsOrDefault(readinessCheck.Data.InvocationTimeout))
+	outputUtils.PrintInfoMessage("  Interval: " + secondsOrDefault(readinessCheck.Data.Interval))
+}
+
+// UpdateHealthCheck updates the health check of the process, values that are not set in the update are kept
+func UpdateHealthCheck(cf *client.Client, process *resource.Process, update *HealthCheckUpdate) (*resource.Process, error) {
+	healthCheck := process.HealthCheck
+	if update.Type != "" {
+		if !isValidHealthCheckType(update.Type) {
+			return nil, fmt.Errorf("invalid health check type '%s' - valid types are %v", update.Type, healthCheckTypes)
+		}
+		healthCheck.Type = update.Type
+	}
+	if update.Endpoint != "" {
+		if healthCheck.Type != "http" {
+			return nil, errors.New("an endpoint can only be set for an http health check")
+		}
+		healthCheck.Data.Endpoint = &update.Endpoint
+	}
+	if healthCheck.Type != "http" {
+		healthCheck.Data.Endpoint = nil
+	}
+	if update.Timeout > 0 {
+		healthCheck.Data.Timeout = &update.Timeout
+	}
+	if update.InvocationTimeout > 0 {
+		healthCheck.Data.InvocationTimeout = &update.InvocationTimeout
+	}
+
+	// resource.ProcessUpdate always sends the command, and a null command resets a custom start command,
+	// so the request is sent with the health check only
+	body, err := json.Marshal(map[string]*resource.ProcessHealthCheck{"health_check": &healthCheck})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPatch, cf.Config.ApiURL("/v3/processes/"+process.GUID), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := cf.ExecuteAuthRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var updated resource.Process
+	if err = json.Unmarshal(responseBody, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func isValidHealthCheckType(healthCheckType string) bool {
+	for _, validType := range healthCheckTypes {
+		if validType == healthCheckType {
+			return true
+		}
+	}
+	return false
+}
+
+func stringOrDefault(value *string, defaultValue string) string {
+	if value == nil || *value == "" {
+		return defaultValue
+	}
+	return *value
+}
+
+func secondsOrDefault(value *int) string {
+	if value == nil {
+		return "default"
+	}
+	return strconv.Itoa(*value) + "s"
+}
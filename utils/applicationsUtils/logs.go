@@ -25,7 +25,8 @@ type log struct {
 }
 
 type logPayload struct {
-	Timestamp string `json:"timestamp"`
+	Timestamp string            `json:"timestamp"`
+	Tags      map[string]string `json:"tags"`
 	Log       struct {
 		Payload string `json:"payload"`
 		Type    string `json:"type"`
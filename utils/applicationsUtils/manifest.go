@@ -0,0 +1,146 @@
+package applicationsUtils
+
+import (
+	"context"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	. "goli-cli/types"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// env vars whose name contains one of these words are replaced by placeholders
+var secretKeywords = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "KEY", "CREDENTIAL", "PRIVATE", "AUTH"}
+
+// GenerateManifest reconstructs the manifest of the app from its live state,
+// it returns the names of the env vars that were replaced by placeholders
+func GenerateManifest(cf *client.Client, appGUID string, env *resource.AppEnvironment, services []string, placeholders bool) (*Manifest, []string, error) {
+	app, err := cf.Applications.Get(context.Background(), appGUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	processes, err := cf.Processes.ListForAppAll(context.Background(), appGUID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	routes, err := cf.Routes.ListForAppAll(context.Background(), appGUID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifestApp := &ManifestApp{
+		Name:       app.Name,
+		Stack:      app.Lifecycle.BuildpackData.Stack,
+		Buildpacks: app.Lifecycle.BuildpackData.Buildpacks,
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		manifestApp.Services = append(manifestApp.Services, ManifestService(service))
+	}
+
+	var replacedEnvs []string
+	if env != nil && len(env.EnvVars) > 0 {
+		manifestApp.Env = make(map[string]string, len(env.EnvVars))
+		for key, value := range env.EnvVars {
+			if placeholders && isSecretEnv(key) {
+				value = "((" + key + "))"
+				replacedEnvs = append(replacedEnvs, key)
+			}
+			manifestApp.Env[key] = value
+		}
+		sort.Strings(replacedEnvs)
+	}
+
+	for _, route := range routes {
+		manifestRoute := &ManifestRoute{Route: route.URL}
+		if route.Protocol != "" && route.Protocol != "http" {
+			manifestRoute.Protocol = route.Protocol
+		}
+		manifestApp.Routes = append(manifestApp.Routes, manifestRoute)
+	}
+	sort.Slice(manifestApp.Routes, func(i, j int) bool {
+		return manifestApp.Routes[i].Route < manifestApp.Routes[j].Route
+	})
+
+	for _, process := range processes {
+		manifestApp.Processes = append(manifestApp.Processes, toManifestProcess(process))
+	}
+	sort.Slice(manifestApp.Processes, func(i, j int) bool {
+		// the web process is always first
+		if manifestApp.Processes[i].Type == "web" || manifestApp.Processes[j].Type == "web" {
+			return manifestApp.Processes[i].Type == "web"
+		}
+		return manifestApp.Processes[i].Type < manifestApp.Processes[j].Type
+	})
+
+	return &Manifest{Applications: []*ManifestApp{manifestApp}}, replacedEnvs, nil
+}
+
+func toManifestProcess(process *resource.Process) *ManifestProcess {
+	instances := process.Instances
+	manifestProcess := &ManifestProcess{
+		Type:            process.Type,
+		Instances:       &instances,
+		Memory:          FormatSizeInMB(process.MemoryInMB),
+		DiskQuota:       FormatSizeInMB(process.DiskInMB),
+		HealthCheckType: process.HealthCheck.Type,
+	}
+	if process.Command != nil {
+		manifestProcess.Command = *process.Command
+	}
+	healthCheck := process.HealthCheck.Data
+	if process.HealthCheck.Type == "http" && healthCheck.Endpoint != nil {
+		manifestProcess.HealthCheckHTTPEndpoint = *healthCheck.Endpoint
+	}
+	if healthCheck.Timeout != nil {
+		manifestProcess.Timeout = *healthCheck.Timeout
+	}
+	if healthCheck.InvocationTimeout != nil {
+		manifestProcess.HealthCheckInvocationTimeout = *healthCheck.InvocationTimeout
+	}
+	// process is the default readiness check, so it is only written when it was changed
+	readinessCheck := process.ReadinessCheck
+	if readinessCheck.Type != "" && readinessCheck.Type != "process" {
+		manifestProcess.ReadinessHealthCheckType = readinessCheck.Type
+		if readinessCheck.Type == "http" && readinessCheck.Data.Endpoint != nil {
+			manifestProcess.ReadinessHealthCheckHTTPEndpoint = *readinessCheck.Data.Endpoint
+		}
+	}
+	return manifestProcess
+}
+
+func MarshalManifest(manifest *Manifest) ([]byte, error) {
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(2)
+	err := encoder.Encode(manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(builder.String()), nil
+}
+
+// FormatSizeInMB formats a size in the manifest notation (e.g. 512M, 1G)
+func FormatSizeInMB(sizeInMB int) string {
+	if sizeInMB >= 1024 && sizeInMB%1024 == 0 {
+		return strconv.Itoa(sizeInMB/1024) + "G"
+	}
+	return strconv.Itoa(sizeInMB) + "M"
+}
+
+func isSecretEnv(key string) bool {
+	upperKey := strings.ToUpper(key)
+	for _, keyword := range secretKeywords {
+		if strings.Contains(upperKey, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package applicationsUtils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"goli-cli/utils"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var exitStatusRegex = regexp.MustCompile(`status (\d+)`)
+
+func CreateTask(cf *client.Client, appGUID, command, name string, memoryInMB int) (*resource.Task, error) {
+	droplet, err := cf.Droplets.GetCurrentForApp(context.Background(), appGUID)
+	if err != nil {
+		return nil, errors.New("cannot get the current droplet of the app: " + err.Error())
+	}
+	taskCreate := &resource.TaskCreate{
+		Command:     &command,
+		DropletGUID: &droplet.GUID,
+	}
+	if name != "" {
+		taskCreate.Name = &name
+	}
+	if memoryInMB > 0 {
+		taskCreate.MemoryInMB = &memoryInMB
+	}
+	return cf.Tasks.Create(context.Background(), appGUID, taskCreate)
+}
+
+func ListRecentTasks(cf *client.Client, appGUID string, limit int) ([]*resource.Task, error) {
+	tasks, _, err := cf.Tasks.ListForApp(context.Background(), appGUID, &client.TaskListOptions{
+		ListOptions: &client.ListOptions{
+			Page:    1,
+			PerPage: limit,
+			OrderBy: "-created_at",
+		},
+	})
+	return tasks, err
+}
+
+// FindTask finds a task of the app by its sequence id, name or GUID
+func FindTask(cf *client.Client, appGUID, identifier string) (*resource.Task, error) {
+	tasks, err := cf.Tasks.ListForAppAll(context.Background(), appGUID, &client.TaskListOptions{
+		ListOptions: &client.ListOptions{
+			OrderBy: "-created_at",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sequenceID, _ := strconv.Atoi(identifier)
+	for _, task := range tasks {
+		if task.SequenceID == sequenceID || task.Name == identifier || task.GUID == identifier {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("task '%s' not found", identifier)
+}
+
+func PrintTasks(tasks []*resource.Task) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Id", "Name", "State", "Command", "Memory", "Created", "Failure Reason"})
+	for _, task := range tasks {
+		failureReason := ""
+		if task.Result.FailureReason != nil {
+			failureReason = *task.Result.FailureReason
+		}
+		table.Append([]string{
+			strconv.Itoa(task.SequenceID),
+			task.Name,
+			colorTaskState(task.State),
+			task.Command,
+			fmt.Sprintf("%dM", task.MemoryInMB),
+			task.CreatedAt.In(location).Format("2006-01-02 15:04:05"),
+			failureReason,
+		})
+	}
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(60)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+func colorTaskState(state string) string {
+	switch state {
+	case "SUCCEEDED":
+		return color.GreenString(state)
+	case "FAILED":
+		return color.RedString(state)
+	case "RUNNING":
+		return color.HiCyanString(state)
+	default:
+		return color.YellowString(state)
+	}
+}
+
+// FollowTask streams the logs of the task from log-cache until the task is finished and returns the final task
+func FollowTask(cf *client.Client, appGUID string, task *resource.Task) (*resource.Task, error) {
+	domain = utils.ExtractDomain(cf.Config.ApiURL(""))
+	sourceType := "APP/TASK/" + task.Name
+	timestamp := task.CreatedAt.Add(-time.Minute).UnixNano()
+	var err error
+
+	fmt.Printf("Following task %s, waiting for it to finish...\n", color.HiCyanString(task.Name))
+	for {
+		timestamp, err = printTaskLogs(cf, appGUID, sourceType, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		task, err = cf.Tasks.Get(context.Background(), task.GUID)
+		if err != nil {
+			return nil, err
+		}
+		if task.State == "SUCCEEDED" || task.State == "FAILED" {
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	// logs may arrive to log-cache after the task is finished
+	time.Sleep(3 * time.Second)
+	_, err = printTaskLogs(cf, appGUID, sourceType, timestamp)
+	return task, err
+}
+
+func printTaskLogs(cf *client.Client, appGUID, sourceType string, timestamp int64) (int64, error) {
+	current := int64(0)
+	for timestamp != current {
+		current = timestamp
+		logs, err := getLogsFromService(cf, appGUID, current)
+		if err != nil {
+			return 0, errors.New("error getting logs from service " + err.Error())
+		}
+		if len(*logs) == 0 {
+			break
+		}
+		timestamp, err = getNextTimestamp(logs)
+		if err != nil {
+			return 0, err
+		}
+		var taskLogs []logPayload
+		for _, logData := range *logs {
+			if logData.Tags["source_type"] == sourceType {
+				taskLogs = append(taskLogs, logData)
+			}
+		}
+		err = printLogs(&taskLogs, "", "")
+		if err != nil {
+			return 0, errors.New("error printing logs " + err.Error())
+		}
+	}
+	return timestamp, nil
+}
+
+// PrintTaskResult prints the final state of the task and returns an error if the task failed
+func PrintTaskResult(task *resource.Task) error {
+	fmt.Println("task", color.HiCyanString(task.Name), "finished with state:", colorTaskState(task.State))
+	if task.State == "SUCCEEDED" {
+		fmt.Println("exit status:", color.GreenString("0"))
+		return nil
+	}
+	failureReason := "unknown"
+	if task.Result.FailureReason != nil {
+		failureReason = *task.Result.FailureReason
+	}
+	if match := exitStatusRegex.FindStringSubmatch(failureReason); match != nil {
+		fmt.Println("exit status:", color.RedString(match[1]))
+	}
+	return errors.New("task failed: " + failureReason)
+}
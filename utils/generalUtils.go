@@ -8,11 +8,13 @@ import (
 	. "goli-cli/types"
 	"goli-cli/utils/outputUtils"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -88,6 +90,24 @@ func StopUntilEnter() {
 	scanner.Scan()
 }
 
+// StopUntilEnterOrInterrupt blocks until the user clicks on 'Enter' or interrupts the process
+func StopUntilEnterOrInterrupt() {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+
+	entered := make(chan bool, 1)
+	go func() {
+		StopUntilEnter()
+		entered <- true
+	}()
+
+	select {
+	case <-interrupted:
+	case <-entered:
+	}
+}
+
 func PresentSecurityQuestion() bool {
 	scanner := bufio.NewReader(os.Stdin)
 	for {
@@ -218,3 +238,24 @@ func PrintTime(line ...string) {
 	}
 	println(line[0], time.Now().Sub(timeNow).Milliseconds())
 }
+
+// ParseSizeInMB parses a size in the cf format (e.g. '512M', '1G', '1024') to megabytes
+func ParseSizeInMB(size string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	value = strings.TrimSuffix(value, "B")
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "T"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1
+	}
+	value = strings.TrimRight(value, "TGM")
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid size '%s' - expected a value such as 512M or 1G", size)
+	}
+	return number * multiplier, nil
+}
@@ -0,0 +1,207 @@
+package graphUtils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"goli-cli/entities"
+	. "goli-cli/types"
+	"sort"
+	"strings"
+)
+
+// the number of instance GUIDs sent in a single bindings request, to keep the URL short
+const bindingsChunkSize = 50
+
+type GraphApp struct {
+	Name  string `json:"name"`
+	GUID  string `json:"guid"`
+	Bound bool   `json:"bound"`
+}
+
+type GraphInstance struct {
+	Name         string `json:"name"`
+	GUID         string `json:"guid"`
+	Offering     string `json:"offering"`
+	Plan         string `json:"plan"`
+	UserProvided bool   `json:"userProvided"`
+	Bound        bool   `json:"bound"`
+}
+
+type GraphBinding struct {
+	App      string `json:"app"`
+	Instance string `json:"instance"`
+}
+
+type Graph struct {
+	Apps      []*GraphApp      `json:"apps"`
+	Instances []*GraphInstance `json:"instances"`
+	Bindings  []*GraphBinding  `json:"bindings"`
+}
+
+// BuildGraph builds the binding graph of the space from the cached apps and instances and the app bindings of the instances
+func BuildGraph(cf *client.Client, apps *map[string]AppData, instancesByOffer *map[string][]*entities.Instance) (*Graph, error) {
+	graph := &Graph{}
+	appsByGUID := make(map[string]*GraphApp)
+	for _, appData := range *apps {
+		app := &GraphApp{Name: appData.Name, GUID: appData.GUID}
+		appsByGUID[app.GUID] = app
+		graph.Apps = append(graph.Apps, app)
+	}
+	instancesByGUID := make(map[string]*GraphInstance)
+	var instanceGUIDs []string
+	for offering, instances := range *instancesByOffer {
+		for _, instanceData := range instances {
+			instance := &GraphInstance{
+				Name:         instanceData.Name,
+				GUID:         instanceData.GUID,
+				Offering:     offering,
+				Plan:         instanceData.Plan,
+				UserProvided: offering == "user-provided",
+			}
+			instancesByGUID[instance.GUID] = instance
+			instanceGUIDs = append(instanceGUIDs, instance.GUID)
+			graph.Instances = append(graph.Instances, instance)
+		}
+	}
+
+	for start := 0; start < len(instanceGUIDs); start += bindingsChunkSize {
+		end := min(start+bindingsChunkSize, len(instanceGUIDs))
+		bindings, boundApps, err := cf.ServiceCredentialBindings.ListIncludeAppsAll(context.Background(), &client.ServiceCredentialBindingListOptions{
+			ListOptions:          client.NewListOptions(),
+			ServiceInstanceGUIDs: client.Filter{Values: instanceGUIDs[start:end]},
+			Type:                 client.Filter{Values: []string{"app"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		// apps that are not in the cache (e.g. the other half of a blue-green deployment) are added from the bindings
+		for _, boundApp := range boundApps {
+			if appsByGUID[boundApp.GUID] == nil {
+				app := &GraphApp{Name: boundApp.Name, GUID: boundApp.GUID}
+				appsByGUID[app.GUID] = app
+				graph.Apps = append(graph.Apps, app)
+			}
+		}
+		for _, binding := range bindings {
+			if binding.Relationships.App == nil || binding.Relationships.ServiceInstance == nil {
+				continue
+			}
+			app := appsByGUID[binding.Relationships.App.Data.GUID]
+			instance := instancesByGUID[binding.Relationships.ServiceInstance.Data.GUID]
+			if app == nil || instance == nil {
+				continue
+			}
+			app.Bound = true
+			instance.Bound = true
+			graph.Bindings = append(graph.Bindings, &GraphBinding{App: app.Name, Instance: instance.Name})
+		}
+	}
+
+	sort.Slice(graph.Apps, func(i, j int) bool { return graph.Apps[i].Name < graph.Apps[j].Name })
+	sort.Slice(graph.Instances, func(i, j int) bool { return graph.Instances[i].Name < graph.Instances[j].Name })
+	sort.Slice(graph.Bindings, func(i, j int) bool {
+		if graph.Bindings[i].App != graph.Bindings[j].App {
+			return graph.Bindings[i].App < graph.Bindings[j].App
+		}
+		return graph.Bindings[i].Instance < graph.Bindings[j].Instance
+	})
+	return graph, nil
+}
+
+func (instance *GraphInstance) label() string {
+	if instance.UserProvided {
+		return "user-provided"
+	}
+	return instance.Offering + " / " + instance.Plan
+}
+
+func RenderGraph(graph *Graph, format string) (string, error) {
+	switch format {
+	case "dot":
+		return renderDot(graph), nil
+	case "mermaid":
+		return renderMermaid(graph), nil
+	case "json":
+		graphJson, err := json.MarshalIndent(graph, "", "  ")
+		return string(graphJson) + "\n", err
+	}
+	return "", fmt.Errorf("invalid format '%s' - valid formats are dot, mermaid and json", format)
+}
+
+func renderDot(graph *Graph) string {
+	var builder strings.Builder
+	builder.WriteString("digraph space {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	builder.WriteString("  node [fontname=\"Helvetica\"];\n\n")
+	for _, app := range graph.Apps {
+		style := `style=filled, fillcolor="#cfe2ff"`
+		if !app.Bound {
+			style = `style="filled,dashed", fillcolor="#f8d7da", color="#dc3545"`
+		}
+		fmt.Fprintf(&builder, "  %s [label=%s, shape=box, %s];\n", dotID("app", app.Name), dotString(app.Name), style)
+	}
+	builder.WriteString("\n")
+	for _, instance := range graph.Instances {
+		style := `style=filled, fillcolor="#d1e7dd"`
+		if !instance.Bound {
+			style = `style="filled,dashed", fillcolor="#f8d7da", color="#dc3545"`
+		}
+		label := instance.Name + "\n" + instance.label()
+		fmt.Fprintf(&builder, "  %s [label=%s, shape=cylinder, %s];\n", dotID("instance", instance.Name), dotString(label), style)
+	}
+	builder.WriteString("\n")
+	for _, binding := range graph.Bindings {
+		fmt.Fprintf(&builder, "  %s -> %s;\n", dotID("app", binding.App), dotID("instance", binding.Instance))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func dotID(kind, name string) string {
+	return dotString(kind + ":" + name)
+}
+
+func dotString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + strings.ReplaceAll(value, "\n", `\n`) + `"`
+}
+
+func renderMermaid(graph *Graph) string {
+	var builder strings.Builder
+	var unbound []string
+	appIDs := make(map[string]string)
+	instanceIDs := make(map[string]string)
+
+	builder.WriteString("graph LR\n")
+	for index, app := range graph.Apps {
+		id := fmt.Sprintf("app%d", index)
+		appIDs[app.Name] = id
+		fmt.Fprintf(&builder, "  %s[\"%s\"]\n", id, mermaidString(app.Name))
+		if !app.Bound {
+			unbound = append(unbound, id)
+		}
+	}
+	for index, instance := range graph.Instances {
+		id := fmt.Sprintf("instance%d", index)
+		instanceIDs[instance.Name] = id
+		fmt.Fprintf(&builder, "  %s[(\"%s<br/>%s\")]\n", id, mermaidString(instance.Name), mermaidString(instance.label()))
+		if !instance.Bound {
+			unbound = append(unbound, id)
+		}
+	}
+	for _, binding := range graph.Bindings {
+		fmt.Fprintf(&builder, "  %s --> %s\n", appIDs[binding.App], instanceIDs[binding.Instance])
+	}
+	if len(unbound) > 0 {
+		builder.WriteString("  classDef unbound fill:#f8d7da,stroke:#dc3545,stroke-dasharray:5 5\n")
+		fmt.Fprintf(&builder, "  class %s unbound\n", strings.Join(unbound, ","))
+	}
+	return builder.String()
+}
+
+func mermaidString(value string) string {
+	return strings.ReplaceAll(value, `"`, "#quot;")
+}
@@ -53,7 +53,7 @@ func UpdateLandscapesData(cf *client.Client, landscapeData *LandscapeData, mutex
 	}()
 	go func() {
 		defer ws.Done()
-		instancesByOffer, _, err = getInstances(cf, domain, selectedSpace.GUID)
+		instancesByOffer, _, err = GetInstances(cf, domain, selectedSpace.GUID)
 		if err != nil {
 			outputUtils.Panic("An error occurred while getting the instances")
 		}
@@ -188,7 +188,7 @@ func InsertAppData(upApps map[string]AppData, originalAppName string, formatedAp
 	}
 }
 
-func getInstances(cf *client.Client, domain string, spaceGUID string) (*map[string][]*entities.Instance, *[]string, error) {
+func GetInstances(cf *client.Client, domain string, spaceGUID string) (*map[string][]*entities.Instance, *[]string, error) {
 	instances := make(map[string][]*entities.Instance)
 	var offersNames []string
 
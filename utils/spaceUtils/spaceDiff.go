@@ -0,0 +1,294 @@
+package spaceUtils
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	. "goli-cli/types"
+	"goli-cli/utils/graphUtils"
+	"goli-cli/utils/setUpUtils"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// the number of droplets fetched in parallel
+const dropletsConcurrency = 10
+
+// blue-green deployments add a suffix to the app name, so apps are matched by the name without it
+var blueGreenSuffix = regexp.MustCompile(`-(blue|green)$`)
+
+type SpaceApp struct {
+	Name        string
+	State       string
+	Instances   int
+	MemoryInMB  int
+	DropletDate time.Time
+}
+
+type SpaceInstance struct {
+	Name     string
+	Offering string
+	Plan     string
+}
+
+type SpaceSnapshot struct {
+	Label     string
+	Apps      map[string]*SpaceApp
+	Instances map[string]*SpaceInstance
+	Bindings  map[string]bool
+	// the blue-green apps that were left out of the comparison
+	Warnings []string
+}
+
+type DiffRow struct {
+	Name  string
+	Field string
+	Left  string
+	Right string
+}
+
+type DiffGroup struct {
+	Title     string
+	OnlyLeft  []string
+	OnlyRight []string
+	Differ    []*DiffRow
+}
+
+// LoadSpace reads the apps, service instances and bindings of the space
+func LoadSpace(cf *client.Client, domain, spaceGUID, label string) (*SpaceSnapshot, error) {
+	snapshot := &SpaceSnapshot{
+		Label:     label,
+		Apps:      make(map[string]*SpaceApp),
+		Instances: make(map[string]*SpaceInstance),
+		Bindings:  make(map[string]bool),
+	}
+
+	apps, err := cf.Applications.ListAll(context.Background(), &client.AppListOptions{
+		SpaceGUIDs:  client.Filter{Values: []string{spaceGUID}},
+		ListOptions: client.NewListOptions(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	processes, err := cf.Processes.ListAll(context.Background(), &client.ProcessListOptions{
+		SpaceGUIDs:  client.Filter{Values: []string{spaceGUID}},
+		Types:       client.Filter{Values: []string{"web"}},
+		ListOptions: client.NewListOptions(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	processByApp := make(map[string]*resource.Process)
+	for _, process := range processes {
+		if process.Relationships.App.Data != nil {
+			processByApp[process.Relationships.App.Data.GUID] = process
+		}
+	}
+
+	appsData := make(map[string]AppData)
+	appsByGUID := make(map[string]*SpaceApp)
+	for _, app := range apps {
+		spaceApp := &SpaceApp{Name: app.Name, State: app.State}
+		if process := processByApp[app.GUID]; process != nil {
+			spaceApp.Instances = process.Instances
+			spaceApp.MemoryInMB = process.MemoryInMB
+		}
+		appsByGUID[app.GUID] = spaceApp
+		appsData[app.Name] = AppData{Name: app.Name, GUID: app.GUID}
+
+		name := blueGreenSuffix.ReplaceAllString(app.Name, "")
+		other := snapshot.Apps[name]
+		if other == nil {
+			snapshot.Apps[name] = spaceApp
+			continue
+		}
+		// both colors exist during a switch-over, the started one is compared, or the first by name if both have the same state
+		kept, skipped := other, spaceApp
+		if spaceApp.State == "STARTED" && other.State != "STARTED" || spaceApp.State == other.State && spaceApp.Name < other.Name {
+			kept, skipped = spaceApp, other
+		}
+		snapshot.Apps[name] = kept
+		snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("%s: both %s (%s) and %s (%s) exist, only %s is compared",
+			label, kept.Name, kept.State, skipped.Name, skipped.State, kept.Name))
+	}
+	err = loadDropletDates(cf, appsByGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	instancesByOffer, _, err := setUpUtils.GetInstances(cf, domain, spaceGUID)
+	if err != nil {
+		return nil, err
+	}
+	for offering, instances := range *instancesByOffer {
+		for _, instance := range instances {
+			snapshot.Instances[instance.Name] = &SpaceInstance{Name: instance.Name, Offering: offering, Plan: instance.Plan}
+		}
+	}
+
+	graph, err := graphUtils.BuildGraph(cf, &appsData, instancesByOffer)
+	if err != nil {
+		return nil, err
+	}
+	for _, binding := range graph.Bindings {
+		name := blueGreenSuffix.ReplaceAllString(binding.App, "")
+		// the bindings of the color that is not compared are left out with its app
+		if app := snapshot.Apps[name]; app != nil && app.Name != binding.App {
+			continue
+		}
+		snapshot.Bindings[name+" -> "+binding.Instance] = true
+	}
+	return snapshot, nil
+}
+
+func loadDropletDates(cf *client.Client, appsByGUID map[string]*SpaceApp) error {
+	var lock sync.Mutex
+	var firstErr error
+	semaphore := make(chan struct{}, dropletsConcurrency)
+	wg := sync.WaitGroup{}
+	for appGUID, app := range appsByGUID {
+		wg.Add(1)
+		go func(appGUID string, app *SpaceApp) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			droplet, err := cf.Droplets.GetCurrentForApp(context.Background(), appGUID)
+			if err != nil {
+				// an app that was never staged has no droplet
+				if !resource.IsResourceNotFoundError(err) {
+					lock.Lock()
+					firstErr = err
+					lock.Unlock()
+				}
+				return
+			}
+			app.DropletDate = droplet.CreatedAt
+		}(appGUID, app)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// DiffSpaces compares the apps, service instances and bindings of the two spaces
+func DiffSpaces(left, right *SpaceSnapshot) []*DiffGroup {
+	appsGroup := &DiffGroup{Title: "Apps"}
+	appsGroup.OnlyLeft, appsGroup.OnlyRight = diffKeys(left.Apps, right.Apps)
+	for _, name := range sortedKeys(left.Apps) {
+		leftApp, rightApp := left.Apps[name], right.Apps[name]
+		if rightApp == nil {
+			continue
+		}
+		addRow := func(field, leftValue, rightValue string) {
+			if leftValue != rightValue {
+				appsGroup.Differ = append(appsGroup.Differ, &DiffRow{Name: name, Field: field, Left: leftValue, Right: rightValue})
+			}
+		}
+		addRow("state", leftApp.State, rightApp.State)
+		addRow("instances", strconv.Itoa(leftApp.Instances), strconv.Itoa(rightApp.Instances))
+		addRow("memory", strconv.Itoa(leftApp.MemoryInMB)+"M", strconv.Itoa(rightApp.MemoryInMB)+"M")
+		// droplets staged on the same day are considered the same
+		if dropletAge(leftApp.DropletDate) != dropletAge(rightApp.DropletDate) {
+			addRow("droplet age", dropletAge(leftApp.DropletDate), dropletAge(rightApp.DropletDate))
+		}
+	}
+
+	instancesGroup := &DiffGroup{Title: "Service instances"}
+	instancesGroup.OnlyLeft, instancesGroup.OnlyRight = diffKeys(left.Instances, right.Instances)
+	for _, name := range sortedKeys(left.Instances) {
+		leftInstance, rightInstance := left.Instances[name], right.Instances[name]
+		if rightInstance == nil {
+			continue
+		}
+		if leftInstance.Offering != rightInstance.Offering {
+			instancesGroup.Differ = append(instancesGroup.Differ, &DiffRow{Name: name, Field: "offering", Left: leftInstance.Offering, Right: rightInstance.Offering})
+		} else if leftInstance.Plan != rightInstance.Plan {
+			instancesGroup.Differ = append(instancesGroup.Differ, &DiffRow{Name: name, Field: "plan", Left: leftInstance.Plan, Right: rightInstance.Plan})
+		}
+	}
+
+	bindingsGroup := &DiffGroup{Title: "Bindings"}
+	bindingsGroup.OnlyLeft, bindingsGroup.OnlyRight = diffKeys(left.Bindings, right.Bindings)
+
+	return []*DiffGroup{appsGroup, instancesGroup, bindingsGroup}
+}
+
+func dropletAge(dropletDate time.Time) string {
+	if dropletDate.IsZero() {
+		return "no droplet"
+	}
+	days := int(time.Since(dropletDate).Hours() / 24)
+	if days == 0 {
+		return "today"
+	}
+	return strconv.Itoa(days) + " days"
+}
+
+func diffKeys[V any](left, right map[string]V) ([]string, []string) {
+	var onlyLeft, onlyRight []string
+	for key := range left {
+		if _, ok := right[key]; !ok {
+			onlyLeft = append(onlyLeft, key)
+		}
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			onlyRight = append(onlyRight, key)
+		}
+	}
+	sort.Strings(onlyLeft)
+	sort.Strings(onlyRight)
+	return onlyLeft, onlyRight
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintSpaceDiff prints the report and returns the number of differences
+func PrintSpaceDiff(groups []*DiffGroup, leftLabel, rightLabel string) int {
+	total := 0
+	for _, group := range groups {
+		count := len(group.OnlyLeft) + len(group.OnlyRight) + len(group.Differ)
+		total += count
+		fmt.Println()
+		fmt.Println(color.HiCyanString(group.Title), color.HiBlackString("(%d differences)", count))
+		printOnly(group.OnlyLeft, leftLabel)
+		printOnly(group.OnlyRight, rightLabel)
+		if len(group.Differ) > 0 {
+			fmt.Println("  differ:")
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Name", "Field", leftLabel, rightLabel})
+			for _, row := range group.Differ {
+				table.Append([]string{row.Name, row.Field, row.Left, row.Right})
+			}
+			table.SetCenterSeparator("")
+			table.SetColumnSeparator("")
+			table.SetColWidth(60)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.Render()
+		}
+	}
+	return total
+}
+
+func printOnly(names []string, label string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Println("  only in", color.YellowString(label)+":")
+	for _, name := range names {
+		fmt.Println("    " + name)
+	}
+}
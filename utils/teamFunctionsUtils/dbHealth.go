@@ -0,0 +1,172 @@
+package teamFunctionsUtils
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"goli-cli/db"
+	"time"
+)
+
+const (
+	// the share of max_connections from which the connections are a warning
+	connectionsWarningRatio = 0.8
+	// tables with at least this number of dead tuples and share of dead tuples are a warning
+	deadTuplesMin     = 10000
+	deadTuplesPercent = 10
+	// cache hit ratios below this percent are a warning
+	cacheHitWarningPercent = 99
+	// the number of rows of the largest tables, largest indexes, unused indexes and top statements
+	healthTopRows = 10
+)
+
+// the sessions of the clients, without the session of the report
+const clientSessions = `FROM pg_stat_activity WHERE backend_type = 'client backend' AND pid <> pg_backend_pid()`
+
+const connectionsQuery = `SELECT count(*) AS total, count(*) FILTER (WHERE state = 'active') AS active,
+	count(*) FILTER (WHERE state = 'idle') AS idle,
+	count(*) FILTER (WHERE state LIKE 'idle in transaction%') AS "idle in transaction",
+	current_setting('max_connections')::int AS max ` + clientSessions
+
+const longQueriesQuery = `SELECT pid, usename AS user, date_trunc('second', now() - query_start)::text AS duration, state,
+	coalesce(wait_event_type || ': ' || wait_event, '') AS waiting, left(regexp_replace(query, '\s+', ' ', 'g'), 80) AS query ` +
+	clientSessions + ` AND state <> 'idle' AND now() - query_start > make_interval(secs => $1)
+	ORDER BY query_start`
+
+// every blocked session with the session that blocks it and the lock it waits for, a chain has a row for every link
+const blockedQuery = `SELECT blocked.pid AS "blocked pid", date_trunc('second', now() - blocked.query_start)::text AS waiting,
+	left(regexp_replace(blocked.query, '\s+', ' ', 'g'), 50) AS "blocked query",
+	blocking.pid AS "blocking pid", blocking.state AS "blocking state",
+	left(regexp_replace(blocking.query, '\s+', ' ', 'g'), 50) AS "blocking query",
+	coalesce(l.relation::regclass::text, l.locktype) AS lock, l.mode
+	FROM pg_stat_activity blocked
+	JOIN LATERAL unnest(pg_blocking_pids(blocked.pid)) AS blocker(pid) ON true
+	JOIN pg_stat_activity blocking ON blocking.pid = blocker.pid
+	LEFT JOIN pg_locks l ON l.pid = blocked.pid AND NOT l.granted
+	ORDER BY blocked.query_start`
+
+const deadTuplesQuery = `SELECT schemaname || '.' || relname AS table, n_live_tup AS live, n_dead_tup AS dead,
+	round(100.0 * n_dead_tup / greatest(n_live_tup + n_dead_tup, 1), 1)::float8 AS "dead %",
+	coalesce(greatest(last_vacuum, last_autovacuum)::timestamp(0)::text, 'never') AS "last vacuum"
+	FROM pg_stat_user_tables
+	WHERE n_dead_tup >= $1 AND 100.0 * n_dead_tup / greatest(n_live_tup + n_dead_tup, 1) >= $2
+	ORDER BY n_dead_tup DESC`
+
+const largestTablesQuery = `SELECT schemaname || '.' || relname AS table, n_live_tup AS rows,
+	pg_size_pretty(pg_total_relation_size(relid)) AS total, pg_size_pretty(pg_relation_size(relid)) AS data,
+	pg_size_pretty(pg_indexes_size(relid)) AS indexes
+	FROM pg_stat_user_tables ORDER BY pg_total_relation_size(relid) DESC LIMIT $1`
+
+const largestIndexesQuery = `SELECT schemaname || '.' || indexrelname AS index, relname AS table, idx_scan AS scans,
+	pg_size_pretty(pg_relation_size(indexrelid)) AS size
+	FROM pg_stat_user_indexes ORDER BY pg_relation_size(indexrelid) DESC LIMIT $1`
+
+// indexes of unique constraints are needed even if they are never scanned
+const unusedIndexesQuery = `SELECT s.schemaname || '.' || s.indexrelname AS index, s.relname AS table,
+	pg_size_pretty(pg_relation_size(s.indexrelid)) AS size
+	FROM pg_stat_user_indexes s JOIN pg_index i ON i.indexrelid = s.indexrelid
+	WHERE s.idx_scan = 0 AND NOT i.indisunique
+	ORDER BY pg_relation_size(s.indexrelid) DESC LIMIT $1`
+
+const cacheHitQuery = `SELECT
+	round(100.0 * sum(heap_blks_hit) / nullif(sum(heap_blks_hit) + sum(heap_blks_read), 0), 2)::float8 AS "table hit %",
+	round(100.0 * sum(idx_blks_hit) / nullif(sum(idx_blks_hit) + sum(idx_blks_read), 0), 2)::float8 AS "index hit %"
+	FROM pg_statio_user_tables`
+
+// total_exec_time is the column of PostgreSQL 13 and later
+const topStatementsQuery = `SELECT calls, round(total_exec_time::numeric, 0)::float8 AS "total ms",
+	round(mean_exec_time::numeric, 2)::float8 AS "mean ms", rows,
+	left(regexp_replace(query, '\s+', ' ', 'g'), 80) AS query
+	FROM pg_stat_statements ORDER BY total_exec_time DESC LIMIT $1`
+
+// HealthQueries returns the queries of the health report, queries that run longer than longQuery are reported
+func HealthQueries(longQuery time.Duration) []db.Query {
+	return []db.Query{
+		{SQL: connectionsQuery},
+		{SQL: longQueriesQuery, Args: []any{longQuery.Seconds()}},
+		{SQL: blockedQuery},
+		{SQL: deadTuplesQuery, Args: []any{deadTuplesMin, deadTuplesPercent}},
+		{SQL: largestTablesQuery, Args: []any{healthTopRows}},
+		{SQL: largestIndexesQuery, Args: []any{healthTopRows}},
+		{SQL: unusedIndexesQuery, Args: []any{healthTopRows}},
+		{SQL: cacheHitQuery},
+		{SQL: topStatementsQuery, Args: []any{healthTopRows}, Optional: true},
+	}
+}
+
+// PrintHealthReport prints the results of HealthQueries as sections with warnings, and returns the number of warnings
+func PrintHealthReport(results []*db.QueryResult, longQuery time.Duration) int {
+	warnings := 0
+	warn := func(format string, args ...any) {
+		fmt.Println(color.HiRedString("! "+format, args...))
+		warnings++
+	}
+
+	printHealthSection("Connections", results[0])
+	connections := results[0].Rows[0]
+	total, idleInTransaction, maxConnections := toFloat(connections[0]), toFloat(connections[3]), toFloat(connections[4])
+	if total >= connectionsWarningRatio*maxConnections {
+		warn("%.0f of the %.0f connections are used", total, maxConnections)
+	}
+	if idleInTransaction > 0 {
+		warn("%.0f sessions are idle in a transaction, they hold their locks and block vacuum", idleInTransaction)
+	}
+
+	printHealthSection("Queries running longer than "+longQuery.String(), results[1])
+	if len(results[1].Rows) > 0 {
+		warn("%d queries are running longer than %s", len(results[1].Rows), longQuery)
+	}
+
+	printHealthSection("Blocked sessions", results[2])
+	if len(results[2].Rows) > 0 {
+		warn("%d sessions are waiting for locks", len(results[2].Rows))
+	}
+
+	printHealthSection(fmt.Sprintf("Tables with at least %d dead tuples and %d%% of their tuples dead", deadTuplesMin, deadTuplesPercent), results[3])
+	if len(results[3].Rows) > 0 {
+		warn("%d tables have many dead tuples, check that autovacuum keeps up with them", len(results[3].Rows))
+	}
+
+	printHealthSection("Largest tables", results[4])
+	printHealthSection("Largest indexes", results[5])
+	printHealthSection("Unused indexes", results[6])
+	if len(results[6].Rows) > 0 {
+		fmt.Println(color.HiBlackString("indexes that were never scanned since the statistics were reset slow down the writes, consider dropping them"))
+	}
+
+	printHealthSection("Cache hit ratio", results[7])
+	for i, relations := range []string{"tables", "indexes"} {
+		if ratio := results[7].Rows[0][i]; ratio != nil && toFloat(ratio) < cacheHitWarningPercent {
+			warn("the cache hit ratio of the %s is %.2f%%, below %d%%, the database may need more memory", relations, toFloat(ratio), cacheHitWarningPercent)
+		}
+	}
+
+	if results[8] == nil {
+		fmt.Println()
+		fmt.Println(color.HiBlackString("pg_stat_statements is not available, install the extension to see the top statements"))
+	} else {
+		printHealthSection("Top statements by total time", results[8])
+	}
+	return warnings
+}
+
+func printHealthSection(title string, result *db.QueryResult) {
+	fmt.Println()
+	fmt.Println(color.HiYellowString(title + ":"))
+	if len(result.Rows) == 0 {
+		fmt.Println(color.HiGreenString("none"))
+		return
+	}
+	PrintCompactTable(result)
+}
+
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
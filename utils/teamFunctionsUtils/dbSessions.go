@@ -0,0 +1,32 @@
+package teamFunctionsUtils
+
+import (
+	"fmt"
+	"goli-cli/db"
+)
+
+// the sessions of the clients with the time since their query started, or since they became idle
+const sessionsQuery = `SELECT pid, usename AS user, application_name AS application, coalesce(client_addr::text, '') AS client, state,
+	date_trunc('second', now() - CASE WHEN state = 'active' THEN query_start ELSE state_change END)::text AS duration,
+	coalesce(wait_event_type || ': ' || wait_event, '') AS waiting, left(regexp_replace(query, '\s+', ' ', 'g'), 60) AS query
+	` + clientSessions + ` AND ($1::boolean OR state <> 'idle') AND ($2::int = 0 OR pid = $2::int)
+	ORDER BY CASE WHEN state = 'active' THEN query_start ELSE state_change END`
+
+// SessionsQuery returns the query of the client sessions, of the non-idle sessions only if activeOnly is set
+func SessionsQuery(activeOnly bool) db.Query {
+	return db.Query{SQL: sessionsQuery, Args: []any{!activeOnly, 0}}
+}
+
+// SessionQuery returns the query of the client session with the pid
+func SessionQuery(pid int) db.Query {
+	return db.Query{SQL: sessionsQuery, Args: []any{true, pid}}
+}
+
+// SignalSessionQuery returns the query that cancels the current query of the session, or terminates the session
+func SignalSessionQuery(pid int, terminate bool) db.Query {
+	function := "pg_cancel_backend"
+	if terminate {
+		function = "pg_terminate_backend"
+	}
+	return db.Query{SQL: fmt.Sprintf("SELECT %s($1)", function), Args: []any{pid}}
+}
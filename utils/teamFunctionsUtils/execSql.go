@@ -0,0 +1,218 @@
+package teamFunctionsUtils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"goli-cli/db"
+	. "goli-cli/types"
+	"goli-cli/utils"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// every run of exec-sql and import-table is appended to the audit log in the goli folder, one JSON object per line
+const sqlAuditLogFile = "sqlAudit.log"
+
+// the outcomes of an exec-sql run in the audit log
+const (
+	OutcomeDryRun    = "dry run, rolled back"
+	OutcomeDeclined  = "declined, rolled back"
+	OutcomeCommitted = "committed"
+	OutcomeFailed    = "failed, rolled back"
+)
+
+type SqlAuditEntry struct {
+	Time       time.Time             `json:"time"`
+	User       string                `json:"user"`
+	Org        string                `json:"org"`
+	Space      string                `json:"space"`
+	Db         string                `json:"db"`
+	File       string                `json:"file"`
+	Script     string                `json:"script"`
+	Commit     bool                  `json:"commit"`
+	Outcome    string                `json:"outcome"`
+	Statements []*db.StatementResult `json:"statements"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// the statements that would end the transaction the script runs in
+var transactionKeywords = []string{"BEGIN", "START", "COMMIT", "END", "ROLLBACK", "ABORT"}
+
+// SplitSQLStatements splits the script on the semicolons that end its statements,
+// the semicolons in string literals, quoted identifiers, dollar-quoted bodies and comments are skipped
+func SplitSQLStatements(script string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+	runes := []rune(script)
+
+	addStatement := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case sqlQuoteStart(runes, i):
+			end, comment, err := sqlQuoteEnd(runes, i)
+			if err != nil {
+				return nil, fmt.Errorf("%v in statement %d", err, len(statements)+1)
+			}
+			current.WriteString(string(runes[i : end+1]))
+			if !comment {
+				hasCode = true
+			}
+			i = end
+		case r == ';':
+			addStatement()
+		default:
+			current.WriteRune(r)
+			if !unicode.IsSpace(r) {
+				hasCode = true
+			}
+		}
+	}
+	addStatement()
+
+	for index, statement := range statements {
+		firstWord := db.FirstKeyword(statement)
+		for _, keyword := range transactionKeywords {
+			if firstWord == keyword {
+				return nil, fmt.Errorf("statement %d: %s is not allowed, the script already runs in a single transaction", index+1, keyword)
+			}
+		}
+	}
+	return statements, nil
+}
+
+// sqlQuoteStart returns true if a comment, string literal, quoted identifier or dollar-quoted body starts at i
+func sqlQuoteStart(runes []rune, i int) bool {
+	r := runes[i]
+	next := rune(0)
+	if i+1 < len(runes) {
+		next = runes[i+1]
+	}
+	return (r == '-' && next == '-') || (r == '/' && next == '*') || r == '\'' || r == '"' || (r == '$' && dollarTag(runes[i:]) != "")
+}
+
+// sqlQuoteEnd returns the index of the last rune of the comment, string literal, quoted identifier or dollar-quoted body
+// that starts at i, and whether it is a comment. A line comment ends before its new line, or at the end of the SQL
+func sqlQuoteEnd(runes []rune, i int) (int, bool, error) {
+	r := runes[i]
+	switch {
+	case r == '-':
+		end := i
+		for end < len(runes) && runes[end] != '\n' {
+			end++
+		}
+		return end - 1, true, nil
+	case r == '/':
+		end := i + 2
+		for end+1 < len(runes) && !(runes[end] == '*' && runes[end+1] == '/') {
+			end++
+		}
+		if end+1 >= len(runes) {
+			return 0, true, errors.New("unterminated comment")
+		}
+		return end + 1, true, nil
+	case r == '\'' || r == '"':
+		// E'' strings escape quotes with backslashes
+		escapes := r == '\'' && i > 0 && (runes[i-1] == 'E' || runes[i-1] == 'e')
+		end := i + 1
+		for end < len(runes) && runes[end] != r {
+			if escapes && runes[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(runes) {
+			return 0, false, errors.New("unterminated quote")
+		}
+		return end, false, nil
+	default:
+		tag := dollarTag(runes[i:])
+		rest := string(runes[i+len([]rune(tag)):])
+		end := strings.Index(rest, tag)
+		if end == -1 {
+			return 0, false, fmt.Errorf("unterminated %s quote", tag)
+		}
+		return i + len([]rune(tag+rest[:end]+tag)) - 1, false, nil
+	}
+}
+
+// dollarTag returns the $tag$ that starts the text, or an empty string if it does not start with a dollar quote
+func dollarTag(runes []rune) string {
+	for end := 1; end < len(runes); end++ {
+		if runes[end] == '$' {
+			return string(runes[:end+1])
+		}
+		if !(unicode.IsLetter(runes[end]) || runes[end] == '_' || (end > 1 && unicode.IsDigit(runes[end]))) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func PrintStatementResults(results []*db.StatementResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Statement", "Result", "Rows"})
+	for index, result := range results {
+		statement := strings.Join(strings.Fields(result.Statement), " ")
+		if len(statement) > 60 {
+			statement = statement[:57] + "..."
+		}
+		outcome := color.HiGreenString(result.CommandTag)
+		if result.Error != "" {
+			outcome = color.HiRedString(result.Error)
+		}
+		table.Append([]string{strconv.Itoa(index + 1), statement, outcome, strconv.FormatInt(result.RowsAffected, 10)})
+	}
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(60)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// AppendSqlAuditEntry appends the entry to the audit log with the current time, user and target
+func AppendSqlAuditEntry(entry *SqlAuditEntry) error {
+	entry.Time = time.Now()
+	entry.User = currentUserEmail()
+	space, org, err := utils.GetOrgAndSpaceFromConfig()
+	if err == nil {
+		entry.Org = org.Name
+		entry.Space = space.Name
+	}
+
+	entryJson, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(sqlAuditLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log: %v", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(entryJson, '\n'))
+	return err
+}
+
+func currentUserEmail() string {
+	var goliConfig LocalConfig
+	configRaw, err := os.ReadFile("config.json")
+	if err != nil {
+		return ""
+	}
+	_ = json.Unmarshal(configRaw, &goliConfig)
+	return goliConfig.Email
+}
@@ -0,0 +1,165 @@
+package teamFunctionsUtils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"math"
+	"strings"
+)
+
+const (
+	// sequential scans that read at least this number of rows are highlighted
+	largeSeqScanRows = 10000
+	// estimates that are off by at least this factor are highlighted, when the larger of the rows is at least mismatchMinRows
+	mismatchFactor  = 10
+	mismatchMinRows = 100
+)
+
+// the fields of EXPLAIN (FORMAT JSON) that are shown, the actual fields are only set with ANALYZE
+type PlanNode struct {
+	NodeType            string      `json:"Node Type"`
+	RelationName        string      `json:"Relation Name"`
+	Alias               string      `json:"Alias"`
+	IndexName           string      `json:"Index Name"`
+	JoinType            string      `json:"Join Type"`
+	StartupCost         float64     `json:"Startup Cost"`
+	TotalCost           float64     `json:"Total Cost"`
+	PlanRows            float64     `json:"Plan Rows"`
+	ActualStartupTime   *float64    `json:"Actual Startup Time"`
+	ActualTotalTime     *float64    `json:"Actual Total Time"`
+	ActualRows          *float64    `json:"Actual Rows"`
+	ActualLoops         float64     `json:"Actual Loops"`
+	IndexCond           string      `json:"Index Cond"`
+	HashCond            string      `json:"Hash Cond"`
+	MergeCond           string      `json:"Merge Cond"`
+	JoinFilter          string      `json:"Join Filter"`
+	Filter              string      `json:"Filter"`
+	RowsRemovedByFilter float64     `json:"Rows Removed by Filter"`
+	SharedHitBlocks     *float64    `json:"Shared Hit Blocks"`
+	SharedReadBlocks    float64     `json:"Shared Read Blocks"`
+	Plans               []*PlanNode `json:"Plans"`
+}
+
+type QueryPlan struct {
+	Plan          *PlanNode `json:"Plan"`
+	PlanningTime  *float64  `json:"Planning Time"`
+	ExecutionTime *float64  `json:"Execution Time"`
+}
+
+func ParseQueryPlan(planJson string) (*QueryPlan, error) {
+	var plans []*QueryPlan
+	err := json.Unmarshal([]byte(planJson), &plans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the query plan: %v", err)
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return nil, errors.New("the database returned an empty query plan")
+	}
+	return plans[0], nil
+}
+
+// PrintQueryPlan prints the plan as an indented tree like psql, with the sequential scans of many rows
+// and the row estimates that are far from the actual rows highlighted, and returns the number of highlights
+func PrintQueryPlan(plan *QueryPlan) int {
+	highlights := printPlanNode(plan.Plan, 0)
+	if plan.PlanningTime != nil {
+		fmt.Printf("Planning Time: %.3f ms\n", *plan.PlanningTime)
+	}
+	if plan.ExecutionTime != nil {
+		fmt.Printf("Execution Time: %.3f ms\n", *plan.ExecutionTime)
+	}
+	return highlights
+}
+
+func printPlanNode(node *PlanNode, depth int) int {
+	highlights := 0
+	// the children are indented by 6 spaces like in psql, with their details under the node type
+	prefix := ""
+	if depth > 0 {
+		prefix = strings.Repeat(" ", 6*(depth-1)+2) + "->  "
+	}
+	details := strings.Repeat(" ", 6*depth+2)
+
+	fmt.Println(prefix + color.HiCyanString(nodeTitle(node)) + "  " + nodeCosts(node))
+	for _, condition := range []struct{ label, value string }{
+		{"Index Cond", node.IndexCond}, {"Hash Cond", node.HashCond}, {"Merge Cond", node.MergeCond},
+		{"Join Filter", node.JoinFilter}, {"Filter", node.Filter},
+	} {
+		if condition.value != "" {
+			fmt.Println(details + condition.label + ": " + condition.value)
+		}
+	}
+	if node.RowsRemovedByFilter > 0 {
+		fmt.Printf("%sRows Removed by Filter: %.0f\n", details, node.RowsRemovedByFilter)
+	}
+	if node.SharedHitBlocks != nil {
+		fmt.Printf("%sBuffers: shared hit=%.0f read=%.0f\n", details, *node.SharedHitBlocks, node.SharedReadBlocks)
+	}
+
+	if rows := scannedRows(node); node.NodeType == "Seq Scan" && rows >= largeSeqScanRows {
+		fmt.Println(details + color.HiYellowString("! sequential scan of %.0f rows on %s, an index on the filtered columns may help", rows, node.RelationName))
+		highlights++
+	}
+	if node.ActualRows != nil && node.ActualLoops > 0 {
+		estimated, actual := node.PlanRows, *node.ActualRows
+		if factor := mismatch(estimated, actual); factor >= mismatchFactor && math.Max(estimated, actual) >= mismatchMinRows {
+			hint := "the statistics may be outdated"
+			if node.RelationName != "" {
+				hint += ", try ANALYZE " + node.RelationName
+			}
+			fmt.Println(details + color.HiRedString("! row estimate off by %.0fx (estimated %.0f, actual %.0f), %s", factor, estimated, actual, hint))
+			highlights++
+		}
+	}
+
+	for _, child := range node.Plans {
+		highlights += printPlanNode(child, depth+1)
+	}
+	return highlights
+}
+
+func nodeTitle(node *PlanNode) string {
+	title := node.NodeType
+	// e.g. Hash Left Join and Nested Loop Anti Join, like psql
+	if node.JoinType != "" && node.JoinType != "Inner" {
+		title = strings.TrimSuffix(node.NodeType, " Join") + " " + node.JoinType + " Join"
+	}
+	if node.IndexName != "" {
+		title += " using " + node.IndexName
+	}
+	if node.RelationName != "" {
+		title += " on " + node.RelationName
+		if node.Alias != "" && node.Alias != node.RelationName {
+			title += " " + node.Alias
+		}
+	}
+	return title
+}
+
+func nodeCosts(node *PlanNode) string {
+	costs := fmt.Sprintf("(cost=%.2f..%.2f rows=%.0f)", node.StartupCost, node.TotalCost, node.PlanRows)
+	if node.ActualRows == nil {
+		return costs
+	}
+	if node.ActualLoops == 0 {
+		return costs + " " + color.HiBlackString("(never executed)")
+	}
+	return costs + " " + color.HiGreenString("(actual time=%.3f..%.3f rows=%.0f loops=%.0f)",
+		*node.ActualStartupTime, *node.ActualTotalTime, *node.ActualRows, node.ActualLoops)
+}
+
+// scannedRows returns the number of rows read by the node, which with ANALYZE includes the rows removed by the filter
+func scannedRows(node *PlanNode) float64 {
+	if node.ActualRows == nil {
+		return node.PlanRows
+	}
+	return (*node.ActualRows + node.RowsRemovedByFilter) * math.Max(node.ActualLoops, 1)
+}
+
+// mismatch returns the factor between the estimated and the actual rows, zero rows count as one
+func mismatch(estimated, actual float64) float64 {
+	estimated, actual = math.Max(estimated, 1), math.Max(actual, 1)
+	return math.Max(estimated/actual, actual/estimated)
+}
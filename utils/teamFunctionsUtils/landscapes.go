@@ -0,0 +1,108 @@
+package teamFunctionsUtils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/cloudfoundry/go-cfclient/v3/config"
+	"goli-cli/entities"
+	"goli-cli/utils/outputUtils"
+	"os"
+	"sort"
+)
+
+// LandscapeInfo is a landscape of resources/landscapesInfo.json, with the technical user of config.json to log in to it
+type LandscapeInfo struct {
+	Name     string
+	API      string `json:"api"`
+	Org      string `json:"org"`
+	Space    string `json:"space"`
+	username string
+	password string
+}
+
+// LoadLandscapes reads the landscapes of resources/landscapesInfo.json, sorted by name, and the technical user of config.json
+func LoadLandscapes() ([]*LandscapeInfo, error) {
+	var dbUserInfo struct {
+		DbCred struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"dbCredentials"`
+	}
+	userConfRaw, err := os.ReadFile("config.json")
+	if err != nil {
+		outputUtils.PrintErrorMessage("config file should be exist")
+		return nil, err
+	}
+	_ = json.Unmarshal(userConfRaw, &dbUserInfo)
+	if dbUserInfo.DbCred.Username == "" || dbUserInfo.DbCred.Password == "" {
+		return nil, errors.New("user credentials are missing in config file")
+	}
+
+	var landscapesInfo map[string]*LandscapeInfo
+	infoRaw, err := os.ReadFile("resources/landscapesInfo.json")
+	if err != nil {
+		outputUtils.PrintErrorMessage("landscapesInfo should be exist in the resources folder with all of the landscapes")
+		return nil, err
+	}
+	if err = json.Unmarshal(infoRaw, &landscapesInfo); err != nil {
+		return nil, fmt.Errorf("invalid landscapesInfo.json: %w", err)
+	}
+
+	var landscapes []*LandscapeInfo
+	for name, landscape := range landscapesInfo {
+		landscape.Name = name
+		landscape.username = dbUserInfo.DbCred.Username
+		landscape.password = dbUserInfo.DbCred.Password
+		landscapes = append(landscapes, landscape)
+	}
+	sort.Slice(landscapes, func(i, j int) bool { return landscapes[i].Name < landscapes[j].Name })
+	return landscapes, nil
+}
+
+// SelectLandscapes returns the landscapes with the names, in the order of the names
+func SelectLandscapes(landscapes []*LandscapeInfo, names []string) ([]*LandscapeInfo, error) {
+	var selected []*LandscapeInfo
+	for _, name := range names {
+		found := false
+		for _, landscape := range landscapes {
+			if landscape.Name == name {
+				selected = append(selected, landscape)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("the landscape %q is not in landscapesInfo.json", name)
+		}
+	}
+	return selected, nil
+}
+
+// PostgresInstance logs in to the landscape with the technical user, and finds the instance with the name in its space
+func (landscape *LandscapeInfo) PostgresInstance(dbName string) (*client.Client, *entities.Instance, error) {
+	cfConf, err := config.New(landscape.API, config.UserPassword(landscape.username, landscape.password), config.SkipTLSValidation())
+	if err != nil {
+		return nil, nil, err
+	}
+	cf, err := client.New(cfConf)
+	if err != nil {
+		return nil, nil, err
+	}
+	space, err := cf.Spaces.First(context.Background(), &client.SpaceListOptions{
+		Names: client.Filter{Values: []string{landscape.Space}},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("the space %s of %s was not found: %w", landscape.Space, landscape.Name, err)
+	}
+	pgInstance, err := cf.ServiceInstances.First(context.Background(), &client.ServiceInstanceListOptions{
+		Names:      client.Filter{Values: []string{dbName}},
+		SpaceGUIDs: client.Filter{Values: []string{space.GUID}},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("the instance %s was not found in %s: %w", dbName, landscape.Space, err)
+	}
+	return cf, &entities.Instance{Name: pgInstance.Name, GUID: pgInstance.GUID}, nil
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"goli-cli/utils"
-	"goli-cli/utils/outputUtils"
 	"io"
 	"net/http"
 	"os"
@@ -144,24 +143,6 @@ func FormatJobStatus(resRaw []byte) ([][]string, error) {
 	return parsedStatus, err
 }
 
-func ValidateQuery(query string) bool {
-	tempQuery := strings.ToLower(query)
-	if !strings.HasPrefix(tempQuery, "select") {
-		outputUtils.PrintErrorMessage("query must begin with 'select'")
-		return false
-	}
-	if !strings.Contains(tempQuery, "from") {
-		outputUtils.PrintErrorMessage("query must have with 'from'")
-		return false
-	}
-	// validate there is no update/ delete / insert
-	if strings.Contains(tempQuery, "update ") || strings.Contains(tempQuery, "delete ") || strings.Contains(tempQuery, "insert ") {
-		outputUtils.PrintErrorMessage("query must not have 'update '/'delete '/'insert '")
-		return false
-	}
-	return true
-}
-
 func GetAndPrintJobStatus(token string, portalUrl string, jobIds []string) error {
 	var parsedStatuses [][]string
 	for _, jobId := range jobIds {
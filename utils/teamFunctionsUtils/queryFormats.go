@@ -0,0 +1,428 @@
+package teamFunctionsUtils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"goli-cli/db"
+	"goli-cli/utils/outputUtils"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	TableFormat    = "table"
+	CsvFormat      = "csv"
+	JsonFormat     = "json"
+	NdjsonFormat   = "ndjson"
+	MarkdownFormat = "markdown"
+	XlsxFormat     = "xlsx"
+)
+
+// DefaultPageSize is the number of rows of every table page printed in the terminal
+const DefaultPageSize = 50
+
+var QueryFormats = []string{TableFormat, CsvFormat, JsonFormat, NdjsonFormat, MarkdownFormat, XlsxFormat}
+
+var formatExtensions = map[string]string{
+	CsvFormat:      ".csv",
+	JsonFormat:     ".json",
+	NdjsonFormat:   ".ndjson",
+	MarkdownFormat: ".md",
+	XlsxFormat:     ".xlsx",
+}
+
+// ResolveQueryFormat validates the format and the file of a query result, and returns them with the defaults applied:
+// without a format the result is printed as a table, or saved in the format of the file extension (CSV for unknown extensions),
+// and the extension of the format is added to the file name if it has none
+func ResolveQueryFormat(format, fileName string) (string, string, error) {
+	if format == "" {
+		format = TableFormat
+		if fileName != "" {
+			format = CsvFormat
+			for extensionFormat, extension := range formatExtensions {
+				if strings.EqualFold(filepath.Ext(fileName), extension) {
+					format = extensionFormat
+				}
+			}
+		}
+	}
+	if !slices.Contains(QueryFormats, format) {
+		return "", "", fmt.Errorf("invalid format '%s' - valid formats are %s", format, strings.Join(QueryFormats, ", "))
+	}
+	if fileName == "" {
+		if format == XlsxFormat {
+			return "", "", fmt.Errorf("the %s format can only be saved to a file, use the --file option", format)
+		}
+		return format, "", nil
+	}
+	if format == TableFormat {
+		return "", "", fmt.Errorf("the %s format can only be printed, use another format to save the result", format)
+	}
+	if filepath.Ext(fileName) == "" {
+		fileName += formatExtensions[format]
+	}
+	return format, fileName, nil
+}
+
+// OutputQueryResult prints the result in the format, or saves it if a file name is provided
+func OutputQueryResult(result *db.QueryResult, format, fileName string) error {
+	if fileName == "" {
+		if format == TableFormat {
+			db.PrintQueryResult(result.StringRows(true))
+			return nil
+		}
+		return WriteQueryResult(os.Stdout, result, format)
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+	err = WriteQueryResult(file, result, format)
+	if err != nil {
+		return err
+	}
+	fileLoc, _ := filepath.Abs(fileName)
+	outputUtils.PrintSuccessMessage("Query result saved to: " + fileLoc)
+	return nil
+}
+
+// StreamQueryResult streams the rows of the query to the terminal in the format, or to the file if a file name is provided.
+// Tables are printed a page at a time, and when the terminal is interactive the user is asked before every next page
+func StreamQueryResult(stream func(writer db.RowWriter, options db.StreamOptions) (*db.QueryStats, error), format, fileName string, options db.StreamOptions) error {
+	if fileName == "" {
+		var writer queryWriter
+		if format == TableFormat {
+			writer = newTableWriter()
+		} else {
+			var err error
+			writer, err = newQueryWriter(os.Stdout, format)
+			if err != nil {
+				return err
+			}
+			options.PageSize = 0
+		}
+		stats, err := stream(writer, options)
+		if err != nil {
+			return err
+		}
+		err = writer.Close()
+		printQueryStats(stats, options)
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+	writer, err := newQueryWriter(file, format)
+	if err != nil {
+		return err
+	}
+	options.PageSize = 0
+	stats, err := stream(writer, options)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// the file would only have a part of the result
+		file.Close()
+		os.Remove(fileName)
+		return err
+	}
+	printQueryStats(stats, options)
+	fileLoc, _ := filepath.Abs(fileName)
+	outputUtils.PrintSuccessMessage("Query result saved to: " + fileLoc)
+	return nil
+}
+
+// printQueryStats prints the number of rows and the time of the query to stderr, so it is not part of a piped result
+func printQueryStats(stats *db.QueryStats, options db.StreamOptions) {
+	rows := fmt.Sprintf("%d rows", stats.Rows)
+	if stats.Rows == 1 {
+		rows = "1 row"
+	}
+	switch {
+	case stats.Truncated && stats.Rows == options.Limit:
+		rows = fmt.Sprintf("first %s, the query has more rows than the limit of %d", rows, options.Limit)
+	case stats.Truncated:
+		rows = fmt.Sprintf("%s shown, the query has more rows", rows)
+	}
+	fmt.Fprintln(os.Stderr, color.HiBlackString("(%s, %s)", rows, stats.Elapsed.Round(time.Millisecond)))
+}
+
+// tableWriter prints the rows of every page as a table
+type tableWriter struct {
+	columns     []string
+	rows        [][]string
+	interactive bool
+	input       *bufio.Reader
+}
+
+func newTableWriter() *tableWriter {
+	return &tableWriter{
+		interactive: readline.IsTerminal(int(os.Stdin.Fd())) && readline.IsTerminal(int(os.Stdout.Fd())),
+		input:       bufio.NewReader(os.Stdin),
+	}
+}
+
+func (writer *tableWriter) WriteColumns(columns []string) error {
+	writer.columns = columns
+	return nil
+}
+
+func (writer *tableWriter) WriteRow(row []any) error {
+	stringRow := make([]string, len(row))
+	for i, value := range row {
+		stringRow[i] = db.FormatValue(value)
+	}
+	writer.rows = append(writer.rows, stringRow)
+	return nil
+}
+
+// EndPage prints the table of the page, and asks for the next page if the terminal is interactive
+func (writer *tableWriter) EndPage(last bool) (bool, error) {
+	db.PrintQueryResult(append([][]string{writer.columns}, writer.rows...))
+	writer.rows = nil
+	if last || !writer.interactive {
+		return true, nil
+	}
+	fmt.Print(color.HiBlackString("-- Press Enter for the next page, or q to quit -- "))
+	answer, err := writer.input.ReadString('\n')
+	if err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(answer) != "q", nil
+}
+
+func (writer *tableWriter) Close() error {
+	return nil
+}
+
+// CombineQueryResults appends the rows of the result to the combined result, with the label in an additional first column
+func CombineQueryResults(combined, result *db.QueryResult, labelColumn, label string) (*db.QueryResult, error) {
+	if combined == nil {
+		combined = &db.QueryResult{Columns: append([]string{labelColumn}, result.Columns...)}
+	} else if !slices.Equal(combined.Columns[1:], result.Columns) {
+		return nil, fmt.Errorf("the columns of the result of %s are different from the other results", label)
+	}
+	for _, row := range result.Rows {
+		combined.Rows = append(combined.Rows, append([]any{label}, row...))
+	}
+	return combined, nil
+}
+
+// WriteQueryResult writes the rows of the result in the format
+func WriteQueryResult(writer io.Writer, result *db.QueryResult, format string) error {
+	queryWriter, err := newQueryWriter(writer, format)
+	if err != nil {
+		return err
+	}
+	err = queryWriter.WriteColumns(result.Columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		err = queryWriter.WriteRow(row)
+		if err != nil {
+			return err
+		}
+	}
+	return queryWriter.Close()
+}
+
+// queryWriter writes the streamed rows of a query in a format, Close writes the end of the format
+type queryWriter interface {
+	db.RowWriter
+	Close() error
+}
+
+func newQueryWriter(writer io.Writer, format string) (queryWriter, error) {
+	buffered := &bufferedWriter{Writer: bufio.NewWriter(writer)}
+	switch format {
+	case CsvFormat:
+		return &csvWriter{bufferedWriter: buffered, csv: csv.NewWriter(buffered)}, nil
+	case JsonFormat:
+		return &jsonWriter{bufferedWriter: buffered}, nil
+	case NdjsonFormat:
+		return &ndjsonWriter{bufferedWriter: buffered}, nil
+	case MarkdownFormat:
+		return &markdownWriter{bufferedWriter: buffered}, nil
+	case XlsxFormat:
+		return newXlsxWriter(buffered), nil
+	}
+	return nil, fmt.Errorf("the %s format cannot be written", format)
+}
+
+// bufferedWriter writes the rows to the output at the end of every page
+type bufferedWriter struct {
+	*bufio.Writer
+}
+
+func (writer *bufferedWriter) EndPage(bool) (bool, error) {
+	return true, writer.Flush()
+}
+
+func (writer *bufferedWriter) Close() error {
+	return writer.Flush()
+}
+
+type csvWriter struct {
+	*bufferedWriter
+	csv *csv.Writer
+}
+
+func (writer *csvWriter) WriteColumns(columns []string) error {
+	return writer.csv.Write(columns)
+}
+
+func (writer *csvWriter) WriteRow(row []any) error {
+	stringRow := make([]string, len(row))
+	for i, value := range row {
+		stringRow[i] = db.FormatValue(value)
+	}
+	err := writer.csv.Write(stringRow)
+	if err != nil {
+		return fmt.Errorf("error writing the CSV: %v", err)
+	}
+	return nil
+}
+
+func (writer *csvWriter) EndPage(last bool) (bool, error) {
+	writer.csv.Flush()
+	return writer.bufferedWriter.EndPage(last)
+}
+
+func (writer *csvWriter) Close() error {
+	writer.csv.Flush()
+	return writer.bufferedWriter.Close()
+}
+
+// jsonWriter writes the rows as an indented array of objects
+type jsonWriter struct {
+	*bufferedWriter
+	columns []string
+	rows    int
+}
+
+func (writer *jsonWriter) WriteColumns(columns []string) error {
+	writer.columns = columns
+	_, err := writer.WriteString("[")
+	return err
+}
+
+func (writer *jsonWriter) WriteRow(row []any) error {
+	rowJson, err := marshalRow(writer.columns, row)
+	if err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	err = json.Indent(&indented, rowJson, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if writer.rows > 0 {
+		writer.WriteString(",")
+	}
+	writer.rows++
+	writer.WriteString("\n  ")
+	_, err = indented.WriteTo(writer)
+	return err
+}
+
+func (writer *jsonWriter) Close() error {
+	if writer.rows > 0 {
+		writer.WriteString("\n")
+	}
+	writer.WriteString("]\n")
+	return writer.bufferedWriter.Close()
+}
+
+type ndjsonWriter struct {
+	*bufferedWriter
+	columns []string
+}
+
+func (writer *ndjsonWriter) WriteColumns(columns []string) error {
+	writer.columns = columns
+	return nil
+}
+
+func (writer *ndjsonWriter) WriteRow(row []any) error {
+	rowJson, err := marshalRow(writer.columns, row)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(rowJson, '\n'))
+	return err
+}
+
+// marshalRow marshals the row as a JSON object with the keys in the order of the columns
+func marshalRow(columns []string, row []any) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		key, _ := marshalJson(column)
+		value, err := marshalJson(row[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert the column %s to JSON: %v", column, err)
+		}
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+// marshalJson marshals the value without escaping HTML characters, since the output is not embedded in HTML
+func marshalJson(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), err
+}
+
+type markdownWriter struct {
+	*bufferedWriter
+}
+
+func (writer *markdownWriter) WriteColumns(columns []string) error {
+	writer.writeMarkdownRow(columns)
+	_, err := writer.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	return err
+}
+
+func (writer *markdownWriter) WriteRow(row []any) error {
+	stringRow := make([]string, len(row))
+	for i, value := range row {
+		stringRow[i] = db.FormatValue(value)
+	}
+	return writer.writeMarkdownRow(stringRow)
+}
+
+func (writer *markdownWriter) writeMarkdownRow(row []string) error {
+	writer.WriteString("|")
+	for _, value := range row {
+		value = strings.ReplaceAll(value, "|", `\|`)
+		value = strings.ReplaceAll(value, "\n", "<br>")
+		writer.WriteString(" " + value + " |")
+	}
+	_, err := writer.WriteString("\n")
+	return err
+}
@@ -0,0 +1,166 @@
+package teamFunctionsUtils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// the saved queries are kept in the goli folder, so they can be shared by copying the file
+const savedQueriesFile = "savedQueries.yml"
+
+type SavedQuery struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// the SQL may contain :name parameters, which are bound when the query is run
+	SQL string `yaml:"sql"`
+	// the name of the DB the query is run on when no DB is specified
+	Db string `yaml:"db,omitempty"`
+}
+
+type savedQueriesFileContent struct {
+	Queries []*SavedQuery `yaml:"queries"`
+}
+
+func LoadSavedQueries() ([]*SavedQuery, error) {
+	var content savedQueriesFileContent
+	file, err := os.ReadFile(savedQueriesFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(file, &content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", savedQueriesFile, err)
+	}
+	return content.Queries, nil
+}
+
+func StoreSavedQueries(queries []*SavedQuery) error {
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	file, err := yaml.Marshal(savedQueriesFileContent{Queries: queries})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(savedQueriesFile, file, 0644)
+}
+
+func FindSavedQuery(queries []*SavedQuery, name string) (*SavedQuery, error) {
+	for _, query := range queries {
+		if query.Name == name {
+			return query, nil
+		}
+	}
+	return nil, fmt.Errorf("saved query %s does not exist, run 'goli team-features saved-queries list' to see the saved queries", name)
+}
+
+func PrintSavedQueries(queries []*SavedQuery) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "DB", "Parameters", "Description"})
+	for _, query := range queries {
+		table.Append([]string{query.Name, query.Db, strings.Join(QueryParamNames(query.SQL), ", "), query.Description})
+	}
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(60)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// ParseQueryParams parses parameters in the key=value form
+func ParseQueryParams(rawParams []string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, rawParam := range rawParams {
+		key, value, found := strings.Cut(rawParam, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid parameter '%s' - parameters must be in the key=value form", rawParam)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// QueryParamNames returns the names of the :name parameters of the SQL in the order of their first appearance
+func QueryParamNames(sql string) []string {
+	var names []string
+	scanQueryParams(sql, func(name string) string {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		return ""
+	})
+	return names
+}
+
+// BindQueryParams replaces the :name parameters of the SQL with positional parameters and returns their values,
+// so the values are sent separately from the SQL and cannot change it
+func BindQueryParams(sql string, params map[string]string) (string, []any, error) {
+	var args []any
+	var missing []string
+	positions := make(map[string]string)
+	boundSQL := scanQueryParams(sql, func(name string) string {
+		if position, ok := positions[name]; ok {
+			return position
+		}
+		value, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		args = append(args, value)
+		positions[name] = "$" + strconv.Itoa(len(args))
+		return positions[name]
+	})
+
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing parameters: %s, use --param %s=<value>", strings.Join(missing, ", "), missing[0])
+	}
+	for name := range params {
+		if _, ok := positions[name]; !ok {
+			return "", nil, errors.New("the query has no parameter " + name)
+		}
+	}
+	return boundSQL, args, nil
+}
+
+// scanQueryParams calls replace for every :name parameter and returns the SQL with the parameters replaced,
+// casts (::type), string literals, quoted identifiers, dollar-quoted bodies and comments are skipped like in SplitSQLStatements
+func scanQueryParams(sql string, replace func(name string) string) string {
+	var builder strings.Builder
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case sqlQuoteStart(runes, i):
+			end, _, err := sqlQuoteEnd(runes, i)
+			if err != nil {
+				// the rest is left as it is, the database reports the unterminated quote
+				builder.WriteString(string(runes[i:]))
+				return builder.String()
+			}
+			builder.WriteString(string(runes[i : end+1]))
+			i = end
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			builder.WriteString("::")
+			i++
+		case r == ':' && i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || runes[i+1] == '_'):
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			builder.WriteString(replace(string(runes[i+1 : end])))
+			i = end - 1
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
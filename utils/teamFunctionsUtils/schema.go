@@ -0,0 +1,160 @@
+package teamFunctionsUtils
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"goli-cli/db"
+	"os"
+	"strings"
+)
+
+// the relations of the user schemas, without the system catalogs
+const userRelations = `
+	FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f')
+	  AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%'`
+
+const relationKind = `CASE c.relkind WHEN 'r' THEN 'table' WHEN 'p' THEN 'partitioned table' WHEN 'v' THEN 'view'
+	WHEN 'm' THEN 'materialized view' WHEN 'f' THEN 'foreign table' END`
+
+// the row estimate is -1 for tables that were never analyzed
+const tablesQuery = `SELECT n.nspname AS schema, c.relname AS table, ` + relationKind + ` AS type,
+	CASE WHEN c.reltuples < 0 OR c.relkind = 'v' THEN NULL ELSE c.reltuples::bigint END AS "rows (estimate)",
+	pg_size_pretty(pg_total_relation_size(c.oid)) AS size` + userRelations + `
+	  AND ($1::text = '' OR n.nspname = $1::text)
+	ORDER BY n.nspname, c.relname`
+
+// the table that matches the name, the exact name is preferred over names that differ in case
+const targetTable = `WITH target AS (SELECT c.oid` + userRelations + `
+	  AND lower(c.relname) = lower($2::text) AND ($1::text = '' OR lower(n.nspname) = lower($1::text))
+	ORDER BY c.relname = $2::text DESC, n.nspname LIMIT 1) `
+
+const matchingTablesQuery = `SELECT n.nspname, c.relname, c.relname = $2::text AS exact, ` + relationKind + ` AS type,
+	CASE WHEN c.reltuples < 0 OR c.relkind = 'v' THEN NULL ELSE c.reltuples::bigint END AS rows,
+	pg_size_pretty(pg_total_relation_size(c.oid)) AS size` + userRelations + `
+	  AND lower(c.relname) = lower($2::text) AND ($1::text = '' OR lower(n.nspname) = lower($1::text))
+	ORDER BY c.relname = $2::text DESC, n.nspname`
+
+const columnsQuery = targetTable + `SELECT a.attname AS column, format_type(a.atttypid, a.atttypmod) AS type,
+	CASE WHEN a.attnotnull THEN 'not null' ELSE '' END AS nullable, coalesce(pg_get_expr(d.adbin, d.adrelid), '') AS default,
+	coalesce(col_description(a.attrelid, a.attnum), '') AS comment
+	FROM target JOIN pg_attribute a ON a.attrelid = target.oid
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+	WHERE a.attnum > 0 AND NOT a.attisdropped
+	ORDER BY a.attnum`
+
+const indexesQuery = targetTable + `SELECT i.indexrelid::regclass::text AS index, pg_get_indexdef(i.indexrelid) AS definition,
+	pg_size_pretty(pg_relation_size(i.indexrelid)) AS size
+	FROM target JOIN pg_index i ON i.indrelid = target.oid
+	ORDER BY i.indisprimary DESC, 1`
+
+const constraintsQuery = targetTable + `SELECT con.conname AS constraint,
+	CASE con.contype WHEN 'p' THEN 'primary key' WHEN 'u' THEN 'unique' WHEN 'c' THEN 'check' WHEN 'x' THEN 'exclusion'
+	WHEN 'f' THEN 'foreign key' ELSE con.contype::text END AS type, pg_get_constraintdef(con.oid) AS definition
+	FROM target JOIN pg_constraint con ON con.conrelid = target.oid
+	ORDER BY con.contype = 'f', con.contype, con.conname`
+
+const referencedByQuery = targetTable + `SELECT con.conrelid::regclass::text AS table, con.conname AS constraint,
+	pg_get_constraintdef(con.oid) AS definition
+	FROM target JOIN pg_constraint con ON con.confrelid = target.oid AND con.contype = 'f'
+	ORDER BY 1, 2`
+
+// TablesQuery returns the query of the tables of the user schemas, or of the schema if it is set
+func TablesQuery(schema string) db.Query {
+	return db.Query{SQL: tablesQuery, Args: []any{schema}}
+}
+
+// TableDetailsQueries returns the queries of the matching tables and of the columns, indexes, constraints
+// and referencing foreign keys of the table, the name may be qualified with the schema
+func TableDetailsQueries(name string) []db.Query {
+	schema, table, found := strings.Cut(name, ".")
+	if !found {
+		schema, table = "", name
+	}
+	args := []any{strings.Trim(schema, `"`), strings.Trim(table, `"`)}
+	var queries []db.Query
+	for _, sql := range []string{matchingTablesQuery, columnsQuery, indexesQuery, constraintsQuery, referencedByQuery} {
+		queries = append(queries, db.Query{SQL: sql, Args: args})
+	}
+	return queries
+}
+
+// PrintTableDetails prints the sections of the results of TableDetailsQueries
+func PrintTableDetails(name string, results []*db.QueryResult) error {
+	matches := results[0].Rows
+	if len(matches) == 0 {
+		return fmt.Errorf("table %s does not exist, run the command without a table to list the tables", name)
+	}
+	// the details are of the first match, which is ambiguous if another match is as exact
+	if len(matches) > 1 && matches[0][2] == matches[1][2] {
+		var names []string
+		for _, match := range matches {
+			names = append(names, fmt.Sprintf("%v.%v", match[0], match[1]))
+		}
+		return fmt.Errorf("table %s is ambiguous, specify one of: %s", name, strings.Join(names, ", "))
+	}
+
+	match := matches[0]
+	summary := fmt.Sprintf("%v", match[3])
+	if match[4] != nil {
+		summary += fmt.Sprintf(", ~%v rows", match[4])
+	}
+	fmt.Println(color.HiCyanString("%v.%v", match[0], match[1]) + " (" + summary + ", " + fmt.Sprintf("%v", match[5]) + ")")
+
+	constraints, foreignKeys := splitForeignKeys(results[3])
+	sections := []struct {
+		title  string
+		result *db.QueryResult
+	}{
+		{"Columns", results[1]},
+		{"Indexes", results[2]},
+		{"Constraints", constraints},
+		{"Foreign keys", foreignKeys},
+		{"Referenced by", results[4]},
+	}
+	for _, section := range sections {
+		if len(section.result.Rows) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Println(color.HiYellowString(section.title + ":"))
+		PrintCompactTable(section.result)
+	}
+	return nil
+}
+
+// splitForeignKeys separates the foreign keys from the other constraints of the table
+func splitForeignKeys(result *db.QueryResult) (*db.QueryResult, *db.QueryResult) {
+	constraints := &db.QueryResult{Columns: result.Columns}
+	foreignKeys := &db.QueryResult{Columns: []string{result.Columns[0], result.Columns[2]}}
+	for _, row := range result.Rows {
+		if row[1] == "foreign key" {
+			foreignKeys.Rows = append(foreignKeys.Rows, []any{row[0], row[2]})
+		} else {
+			constraints.Rows = append(constraints.Rows, row)
+		}
+	}
+	return constraints, foreignKeys
+}
+
+// PrintCompactTable prints the result as a compact table without row lines
+func PrintCompactTable(result *db.QueryResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	rows := result.StringRows(false)
+	for _, row := range rows {
+		for i, value := range row {
+			if value == "NULL" {
+				row[i] = ""
+			}
+		}
+	}
+	table.SetHeader(result.Columns)
+	table.AppendBulk(rows)
+	table.SetAutoFormatHeaders(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetColWidth(80)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
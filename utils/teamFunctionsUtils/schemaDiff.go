@@ -0,0 +1,122 @@
+package teamFunctionsUtils
+
+import (
+	"goli-cli/db"
+	"goli-cli/utils/spaceUtils"
+	"sort"
+)
+
+// the relations of the compared schemas, named schema.relation
+const diffRelations = `WITH relations AS (SELECT c.oid, n.nspname || '.' || c.relname AS name, ` + relationKind + ` AS kind` + userRelations + `
+	  AND ($1::text = '' OR n.nspname = $1::text)) `
+
+const snapshotTablesQuery = diffRelations + `SELECT name, '' AS object, kind FROM relations`
+
+const snapshotColumnsQuery = diffRelations + `SELECT r.name, a.attname, format_type(a.atttypid, a.atttypmod),
+	CASE WHEN a.attnotnull THEN 'not null' ELSE 'null' END, coalesce(pg_get_expr(d.adbin, d.adrelid), '')
+	FROM relations r JOIN pg_attribute a ON a.attrelid = r.oid AND a.attnum > 0 AND NOT a.attisdropped
+	LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum`
+
+const snapshotIndexesQuery = diffRelations + `SELECT r.name, ci.relname, pg_get_indexdef(i.indexrelid)
+	FROM relations r JOIN pg_index i ON i.indrelid = r.oid JOIN pg_class ci ON ci.oid = i.indexrelid`
+
+// the not null constraints of PostgreSQL 18 are compared as part of the columns
+const snapshotConstraintsQuery = diffRelations + `SELECT r.name, con.conname, pg_get_constraintdef(con.oid)
+	FROM relations r JOIN pg_constraint con ON con.conrelid = r.oid WHERE con.contype <> 'n'`
+
+// SchemaSnapshot holds the tables, columns, indexes and constraints of a database, keyed by their qualified names
+type SchemaSnapshot struct {
+	Label       string
+	Tables      map[string]*schemaObject
+	Columns     map[string]*schemaObject
+	Indexes     map[string]*schemaObject
+	Constraints map[string]*schemaObject
+}
+
+type schemaObject struct {
+	Table  string
+	Values []string
+}
+
+// SchemaSnapshotQueries returns the queries of NewSchemaSnapshot, of the schema only if it is set
+func SchemaSnapshotQueries(schema string) []db.Query {
+	var queries []db.Query
+	for _, query := range []string{snapshotTablesQuery, snapshotColumnsQuery, snapshotIndexesQuery, snapshotConstraintsQuery} {
+		queries = append(queries, db.Query{SQL: query, Args: []any{schema}})
+	}
+	return queries
+}
+
+// NewSchemaSnapshot creates the snapshot from the results of SchemaSnapshotQueries
+func NewSchemaSnapshot(label string, results []*db.QueryResult) *SchemaSnapshot {
+	objects := func(result *db.QueryResult) map[string]*schemaObject {
+		byName := make(map[string]*schemaObject, len(result.Rows))
+		for _, row := range result.Rows {
+			// table, object, values...
+			object := &schemaObject{Table: db.FormatValue(row[0])}
+			for _, value := range row[2:] {
+				object.Values = append(object.Values, db.FormatValue(value))
+			}
+			name := object.Table
+			if objectName := db.FormatValue(row[1]); objectName != "" {
+				name += "." + objectName
+			}
+			byName[name] = object
+		}
+		return byName
+	}
+	return &SchemaSnapshot{
+		Label:       label,
+		Tables:      objects(results[0]),
+		Columns:     objects(results[1]),
+		Indexes:     objects(results[2]),
+		Constraints: objects(results[3]),
+	}
+}
+
+// DiffSchemas compares the tables, columns, indexes and constraints of the two snapshots,
+// the objects of a table that exists in only one of them are reported by the table alone
+func DiffSchemas(left, right *SchemaSnapshot) []*spaceUtils.DiffGroup {
+	return []*spaceUtils.DiffGroup{
+		diffSchemaObjects("Tables", []string{"type"}, left.Tables, right.Tables, nil, nil),
+		diffSchemaObjects("Columns", []string{"type", "nullable", "default"}, left.Columns, right.Columns, left.Tables, right.Tables),
+		diffSchemaObjects("Indexes", []string{"definition"}, left.Indexes, right.Indexes, left.Tables, right.Tables),
+		diffSchemaObjects("Constraints", []string{"definition"}, left.Constraints, right.Constraints, left.Tables, right.Tables),
+	}
+}
+
+func diffSchemaObjects(title string, fields []string, left, right, leftTables, rightTables map[string]*schemaObject) *spaceUtils.DiffGroup {
+	group := &spaceUtils.DiffGroup{Title: title}
+	inTable := func(tables map[string]*schemaObject, object *schemaObject) bool {
+		return tables == nil || tables[object.Table] != nil
+	}
+	for _, name := range sortedObjectNames(left) {
+		leftObject, rightObject := left[name], right[name]
+		if rightObject == nil {
+			if inTable(rightTables, leftObject) {
+				group.OnlyLeft = append(group.OnlyLeft, name)
+			}
+			continue
+		}
+		for i, field := range fields {
+			if leftObject.Values[i] != rightObject.Values[i] {
+				group.Differ = append(group.Differ, &spaceUtils.DiffRow{Name: name, Field: field, Left: leftObject.Values[i], Right: rightObject.Values[i]})
+			}
+		}
+	}
+	for _, name := range sortedObjectNames(right) {
+		if left[name] == nil && inTable(leftTables, right[name]) {
+			group.OnlyRight = append(group.OnlyRight, name)
+		}
+	}
+	return group
+}
+
+func sortedObjectNames(objects map[string]*schemaObject) []string {
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,7 +1,6 @@
 package teamFunctionsUtils
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
@@ -12,45 +11,105 @@ import (
 	. "goli-cli/types"
 	"goli-cli/utils"
 	"goli-cli/utils/instanceUtils"
-	"goli-cli/utils/outputUtils"
-	"log"
+	"io"
 	"os"
-	"strings"
 	"sync"
+	"time"
 )
 
-func ConnectAndPrintQuery(cf *client.Client, pgInstanceRaw *entities.Instance, query, fileName string) error {
-	app := instanceUtils.GetFirstStartedApp(cf, pgInstanceRaw.GUID, true)
-	if app == nil {
-		return errors.New("no container is exist to create the ssh tunnel with")
-	}
-	pgInstance := instancesTypes.GetManagedInstance("postgresql-db", pgInstanceRaw, cf)
-	postgresCred, err := GetPostgresCredentials(cf, nil, "", pgInstance)
+// ConnectAndPrintQuery streams the rows of the read-only query on the instance to the terminal or the file, see StreamQueryResult
+func ConnectAndPrintQuery(cf *client.Client, pgInstanceRaw *entities.Instance, query, format, fileName string, statementTimeout time.Duration, options db.StreamOptions, args ...any) error {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
 	if err != nil {
 		return err
 	}
-	stopChan, err := db.OpenConnectionToService(cf, postgresCred, app.GUID, "postgres", app.Name)
+	defer func() { stopChan <- os.Interrupt }()
+	return StreamQueryResult(func(writer db.RowWriter, options db.StreamOptions) (*db.QueryStats, error) {
+		return db.StreamQuery(localCred, query, statementTimeout, options, writer, args...)
+	}, format, fileName, options)
+}
+
+// RunQueryOnInstance runs the read-only query through a tunnel to the first started app bound to the instance, and reads at most limit rows if the limit is set
+func RunQueryOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, query string, statementTimeout time.Duration, limit int, args ...any) (*db.QueryResult, error) {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	rows, err := db.RunQuery(postgresCred, query, true)
+	result, err := db.RunTypedQuery(localCred, query, statementTimeout, limit, args...)
 	stopChan <- os.Interrupt
+	return result, err
+}
+
+// RunQueriesOnInstance runs the read-only queries through a single tunnel and connection to the instance, see db.RunTypedQueries
+func RunQueriesOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, statementTimeout time.Duration, queries ...db.Query) ([]*db.QueryResult, error) {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	results, err := db.RunTypedQueries(localCred, statementTimeout, queries...)
+	stopChan <- os.Interrupt
+	return results, err
+}
 
-	if fileName != "" {
-		if !strings.HasSuffix(fileName, ".csv") {
-			fileName = fileName + ".csv"
-		}
-		// Save the result to a CSV file
-		err = saveToScv(rows, fileName)
-	} else {
-		// print the query result if no file name is provided
-		db.PrintQueryResult(rows)
+// ExplainOnInstance returns the JSON plan of the query through a tunnel to the first started app bound to the instance, see db.ExplainQuery
+func ExplainOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, query string, analyze bool, statementTimeout time.Duration) (string, error) {
+	// EXPLAIN ANALYZE runs the query
+	if err := db.CheckQueryKeyword(query); err != nil {
+		return "", err
+	}
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
+	if err != nil {
+		return "", err
+	}
+	plan, err := db.ExplainQuery(localCred, query, analyze, statementTimeout)
+	stopChan <- os.Interrupt
+	return plan, err
+}
 
+// ExecScriptOnInstance runs the statements in a transaction through a tunnel to the first started app bound to the instance, see db.ExecScript
+func ExecScriptOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, statements []string, statementTimeout time.Duration, confirm func(results []*db.StatementResult) bool) ([]*db.StatementResult, bool, error) {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
+	if err != nil {
+		return nil, false, err
 	}
-	return err
+	results, committed, err := db.ExecScript(localCred, statements, statementTimeout, confirm)
+	stopChan <- os.Interrupt
+	return results, committed, err
+}
+
+// ExportTableOnInstance writes the rows of the table as CSV through a tunnel to the first started app bound to the instance, see db.CopyTo
+func ExportTableOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, table, where string, statementTimeout time.Duration, writer io.Writer) (int64, error) {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := db.CopyTo(localCred, table, where, statementTimeout, writer)
+	stopChan <- os.Interrupt
+	return rows, err
+}
+
+// ImportTableOnInstance copies the CSV rows into the table through a tunnel to the first started app bound to the instance, see db.CopyFrom
+func ImportTableOnInstance(cf *client.Client, pgInstanceRaw *entities.Instance, table string, columns []string, reader io.Reader, statementTimeout time.Duration, confirm func(result *db.StatementResult) bool) (*db.StatementResult, bool, error) {
+	stopChan, localCred, err := openInstanceTunnel(cf, pgInstanceRaw)
+	if err != nil {
+		return nil, false, err
+	}
+	result, committed, err := db.CopyFrom(localCred, table, columns, reader, statementTimeout, confirm)
+	stopChan <- os.Interrupt
+	return result, committed, err
+}
+
+func openInstanceTunnel(cf *client.Client, pgInstanceRaw *entities.Instance) (chan os.Signal, *ConnectionInfo, error) {
+	app := instanceUtils.GetFirstStartedApp(cf, pgInstanceRaw.GUID, true)
+	if app == nil {
+		return nil, nil, errors.New("no container is exist to create the ssh tunnel with")
+	}
+	pgInstance := instancesTypes.GetManagedInstance("postgresql-db", pgInstanceRaw, cf)
+	postgresCred, err := GetPostgresCredentials(cf, nil, "", pgInstance)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db.OpenConnectionToService(cf, postgresCred, app.GUID, "postgres", app.Name, 0)
 }
 
 func GetPostgresCredentials(cf *client.Client, instances *map[string][]*entities.Instance, postgresName string, postgresIns ManagedInstance) (*ConnectionInfo, error) {
@@ -76,7 +135,7 @@ func GetPostgresCredentials(cf *client.Client, instances *map[string][]*entities
 	return connectionInfo, err
 }
 
-func ConnectToDB(cf *client.Client, instances *map[string][]*entities.Instance, dbRawInstance *entities.Instance) error {
+func ConnectToDB(cf *client.Client, instances *map[string][]*entities.Instance, dbRawInstance *entities.Instance, localPort int, console bool) error {
 	ws := sync.WaitGroup{}
 	var err error
 	var postgresCred *ConnectionInfo
@@ -101,7 +160,7 @@ func ConnectToDB(cf *client.Client, instances *map[string][]*entities.Instance,
 	if app == nil {
 		return errors.New("no container is exist to create the ssh tunnel with")
 	}
-	err = db.OpenPostgresConnection(cf, postgresCred, app.GUID, app.Name)
+	err = db.OpenPostgresConnection(cf, postgresCred, app.GUID, app.Name, localPort, console)
 	return err
 }
 
@@ -129,30 +188,3 @@ func GetPostgresInstance(dbName string, instances *map[string][]*entities.Instan
 	}
 	return pgInstanceRaw, nil
 }
-
-func saveToScv(rows [][]string, fileName string) error {
-	// Create CSV file
-	file, err := os.Create(fileName)
-	if err != nil {
-		return errors.New("error creating file")
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	if err := writer.Write(rows[0]); err != nil {
-		log.Fatalf("Failed to write header: %v", err)
-	}
-
-	// Write rows3
-	for _, row := range rows[1:] {
-		if err := writer.Write(row); err != nil {
-			return errors.New("error writing row to CSV")
-		}
-	}
-	fileLoc, _ := os.Getwd()
-	outputUtils.PrintSuccessMessage("Query result saved to: " + fileLoc + "/" + fileName)
-	return nil
-}
@@ -0,0 +1,29 @@
+package teamFunctionsUtils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReadCSVHeader reads the header line of the CSV, so the reader is left at the first row,
+// the column names must not contain line breaks
+func ReadCSVHeader(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if line == "" && err != nil {
+		return nil, errors.New("the file is empty, the first line must be the header with the column names")
+	}
+	columns, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+		if columns[i] == "" {
+			return nil, fmt.Errorf("the column %d of the header has no name", i+1)
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,131 @@
+package teamFunctionsUtils
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"goli-cli/db"
+	"io"
+	"strconv"
+)
+
+// the minimal parts of an xlsx workbook with a single sheet, the sheet itself is generated from the result
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Query result" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+// xlsxWriter writes the rows to the sheet of the workbook while they are read, the other parts are written first
+type xlsxWriter struct {
+	*bufferedWriter
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func newXlsxWriter(buffered *bufferedWriter) *xlsxWriter {
+	return &xlsxWriter{bufferedWriter: buffered, zip: zip.NewWriter(buffered)}
+}
+
+func (writer *xlsxWriter) WriteColumns(columns []string) error {
+	for _, part := range xlsxParts {
+		partWriter, err := writer.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(partWriter, part.content)
+		if err != nil {
+			return err
+		}
+	}
+
+	sheet, err := writer.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	writer.sheet = sheet
+	io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n")
+	io.WriteString(sheet, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	return writer.WriteRow(header)
+}
+
+func (writer *xlsxWriter) WriteRow(row []any) error {
+	writer.rows++
+	return writeXlsxRow(writer.sheet, writer.rows, row)
+}
+
+func (writer *xlsxWriter) Close() error {
+	_, err := io.WriteString(writer.sheet, `</sheetData></worksheet>`)
+	if err != nil {
+		return err
+	}
+	err = writer.zip.Close()
+	if err != nil {
+		return err
+	}
+	return writer.bufferedWriter.Close()
+}
+
+// writeXlsxRow writes the numbers and booleans as typed cells, NULL as an empty cell, and everything else as text
+func writeXlsxRow(writer io.Writer, rowNumber int, row []any) error {
+	fmt.Fprintf(writer, `<row r="%d">`, rowNumber)
+	for i, value := range row {
+		ref := xlsxColumn(i) + strconv.Itoa(rowNumber)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case bool:
+			cell := "0"
+			if v {
+				cell = "1"
+			}
+			fmt.Fprintf(writer, `<c r="%s" t="b"><v>%s</v></c>`, ref, cell)
+			continue
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+			// NaN and infinity are converted to strings by the scan, so every number is valid here
+			fmt.Fprintf(writer, `<c r="%s"><v>%v</v></c>`, ref, v)
+			continue
+		}
+		fmt.Fprintf(writer, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		xml.EscapeText(writer, []byte(db.FormatValue(value)))
+		io.WriteString(writer, `</t></is></c>`)
+	}
+	_, err := io.WriteString(writer, `</row>`)
+	return err
+}
+
+// xlsxColumn returns the letters of the column, e.g. A for 0 and AA for 26
+func xlsxColumn(index int) string {
+	column := ""
+	for index >= 0 {
+		column = string(rune('A'+index%26)) + column
+		index = index/26 - 1
+	}
+	return column
+}